@@ -0,0 +1,641 @@
+package gojson
+
+import (
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxBindDepth limits nesting of values bound by Decode and FromValue, deeper values are most likely cyclic
+const maxBindDepth = 1000
+
+// BindError describes value that can't be bound between GoJSON and Go value
+type BindError struct {
+	Pointer string // JSON Pointer of the value, empty for the root
+	Err     error
+}
+
+func (e *BindError) Error() string {
+	if e.Pointer == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Pointer, e.Err)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+func bindErrorf(path []string, format string, args ...interface{}) error {
+	return &BindError{Pointer: FormatPointer(path...), Err: fmt.Errorf(format, args...)}
+}
+
+var (
+	goJSONType          = reflect.TypeOf(GoJSON{})
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// structField is field of struct bound to object member
+type structField struct {
+	name      string
+	index     []int // index sequence for reflect.Value.FieldByIndex
+	omitEmpty bool
+	asString  bool // scalar is written as json text inside a string
+	tagged    bool // name comes from json tag
+}
+
+// fieldCache maps reflect.Type of struct to its []structField
+var fieldCache sync.Map
+
+// typeFields returns fields of struct type t bound to object members,
+// fields of embedded structs are promoted the way encoding/json does it:
+// shallower fields hide deeper ones, of fields at the same depth only a single tagged one survives
+func typeFields(t reflect.Type) []structField {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]structField)
+	}
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	var fields []structField
+	hidden := make(map[string]bool)
+	visited := make(map[reflect.Type]bool)
+	for current := []embedded{{typ: t}}; len(current) > 0; {
+		var next []embedded
+		var level []structField
+		count := make(map[string]int)
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				options := strings.Split(tag, ",")
+				index := append(e.index[:len(e.index):len(e.index)], i)
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous && options[0] == "" && ft.Kind() == reflect.Struct {
+					next = append(next, embedded{typ: ft, index: index})
+					continue
+				}
+				if !sf.IsExported() {
+					continue
+				}
+				f := structField{name: options[0], index: index, tagged: options[0] != ""}
+				if f.name == "" {
+					f.name = sf.Name
+				}
+				for _, option := range options[1:] {
+					switch option {
+					case "omitempty":
+						f.omitEmpty = true
+					case "string":
+						switch ft.Kind() {
+						case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+							reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+							reflect.Float32, reflect.Float64, reflect.String:
+							f.asString = true
+						}
+					}
+				}
+				if !hidden[f.name] {
+					level = append(level, f)
+					count[f.name]++
+				}
+			}
+		}
+		for _, f := range level {
+			if count[f.name] > 1 {
+				tagged := 0
+				for _, other := range level {
+					if other.name == f.name && other.tagged {
+						tagged++
+					}
+				}
+				if !f.tagged || tagged > 1 {
+					continue
+				}
+			}
+			fields = append(fields, f)
+		}
+		for name := range count {
+			hidden[name] = true
+		}
+		current = next
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].index, fields[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	cached, _ := fieldCache.LoadOrStore(t, fields)
+	return cached.([]structField)
+}
+
+// fieldByIndex returns field of struct v, nil embedded pointers are allocated if alloc is set,
+// false is returned if the field is behind nil pointer that isn't or can't be allocated
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// region Decode
+
+// Decode stores node into value pointed by dst honouring json struct tags: field names, omitempty, string and "-",
+// embedded struct fields are promoted, members without matching field are ignored,
+// time.Time and other encoding.TextUnmarshaler types are decoded from strings, []byte from base64 string or array, *GoJSON and GoJSON get a copy of the node,
+// null sets pointers, maps, slices and interfaces to nil and leaves other values untouched,
+// interface{} gets map[string]interface{}, []interface{}, string, bool, nil,
+// int64 for integers that fit it and float64 for other numbers,
+// errors are returned as *BindError with JSON Pointer of the value that doesn't fit
+func Decode(node *GoJSON, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return &BindError{Err: fmt.Errorf("destination must be a non-nil pointer, got %T", dst)}
+	}
+	if node == nil || node.Type == JSONInvalid {
+		return &BindError{Err: errors.New("node has no value")}
+	}
+	return decodeValue(node, v.Elem(), nil)
+}
+
+func decodeValue(node *GoJSON, v reflect.Value, path []string) error {
+	if len(path) > maxBindDepth {
+		return bindErrorf(path, "nesting exceeds %d levels", maxBindDepth)
+	}
+	t := v.Type()
+	if node.Type == JSONNull {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			v.Set(reflect.Zero(t))
+		}
+		return nil
+	}
+	if t == goJSONType {
+		v.Set(reflect.ValueOf(node.Clone()).Elem())
+		v.Addr().Interface().(*GoJSON).adopt()
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return decodeValue(node, v.Elem(), path)
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		if node.Type != JSONString {
+			return bindErrorf(path, "cannot decode %s into %s", typeName(node), t)
+		}
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(node.Bytes); err != nil {
+			return &BindError{Pointer: FormatPointer(path...), Err: err}
+		}
+		return nil
+	}
+
+	mismatch := false
+	switch t.Kind() {
+	case reflect.Bool:
+		if mismatch = node.Type != JSONBool; !mismatch {
+			b, _ := node.ValueBool()
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if mismatch = !isNumber(node); !mismatch {
+			i, err := node.ValueInt64()
+			if err == nil && v.OverflowInt(i) {
+				err = ErrOverflow
+			}
+			if err != nil {
+				return bindErrorf(path, "cannot decode %s into %s: %w", node.Bytes, t, err)
+			}
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if mismatch = !isNumber(node); !mismatch {
+			u, err := node.ValueUint64()
+			if err == nil && v.OverflowUint(u) {
+				err = ErrOverflow
+			}
+			if err != nil {
+				return bindErrorf(path, "cannot decode %s into %s: %w", node.Bytes, t, err)
+			}
+			v.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		if mismatch = !isNumber(node); !mismatch {
+			f, err := node.ValueFloat()
+			if err == nil && v.OverflowFloat(f) {
+				err = ErrOverflow
+			}
+			if err != nil {
+				return bindErrorf(path, "cannot decode %s into %s: %w", node.Bytes, t, err)
+			}
+			v.SetFloat(f)
+		}
+	case reflect.String:
+		if mismatch = node.Type != JSONString; !mismatch {
+			v.SetString(string(node.Bytes))
+		}
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			v.Set(reflect.ValueOf(interfaceValue(node)))
+		} else if !v.IsNil() && v.Elem().Kind() == reflect.Ptr {
+			return decodeValue(node, v.Elem(), path)
+		} else {
+			mismatch = true
+		}
+	case reflect.Struct:
+		if mismatch = node.Type != JSONObject; !mismatch {
+			return decodeStruct(node, v, path)
+		}
+	case reflect.Map:
+		if mismatch = node.Type != JSONObject; !mismatch {
+			return decodeMap(node, v, path)
+		}
+	case reflect.Slice:
+		if isByteSlice(t) && node.Type == JSONString {
+			b, err := base64.StdEncoding.DecodeString(string(node.Bytes))
+			if err != nil {
+				return bindErrorf(path, "cannot decode base64 string into %s: %w", t, err)
+			}
+			v.SetBytes(b)
+			return nil
+		}
+		if mismatch = node.Type != JSONArray; !mismatch {
+			slice := reflect.MakeSlice(t, len(node.Array), len(node.Array))
+			for i, item := range node.Array {
+				if err := decodeValue(item, slice.Index(i), appendToken(path, strconv.Itoa(i))); err != nil {
+					return err
+				}
+			}
+			v.Set(slice)
+		}
+	case reflect.Array:
+		if mismatch = node.Type != JSONArray; !mismatch {
+			for i := 0; i < v.Len(); i++ {
+				if i >= len(node.Array) {
+					v.Index(i).Set(reflect.Zero(t.Elem()))
+				} else if err := decodeValue(node.Array[i], v.Index(i), appendToken(path, strconv.Itoa(i))); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		return bindErrorf(path, "unsupported type %s", t)
+	}
+	if mismatch {
+		return bindErrorf(path, "cannot decode %s into %s", typeName(node), t)
+	}
+	return nil
+}
+
+// decodeStruct sets fields of v from members of object node, names are matched exactly first and case-insensitively then
+func decodeStruct(node *GoJSON, v reflect.Value, path []string) error {
+	fields := typeFields(v.Type())
+	for _, key := range node.Keys() {
+		var field *structField
+		for i := range fields {
+			if fields[i].name == key {
+				field = &fields[i]
+				break
+			}
+		}
+		for i := 0; field == nil && i < len(fields); i++ {
+			if strings.EqualFold(fields[i].name, key) {
+				field = &fields[i]
+			}
+		}
+		if field == nil {
+			continue
+		}
+		memberPath := appendToken(path, key)
+		fv, ok := fieldByIndex(v, field.index, true)
+		if !ok {
+			return bindErrorf(memberPath, "cannot set embedded pointer to unexported struct in %s", v.Type())
+		}
+		member := node.Map[key]
+		if field.asString && member.Type != JSONNull {
+			var err error
+			if member, err = unquoteMember(member, fv.Type()); err != nil {
+				return &BindError{Pointer: FormatPointer(memberPath...), Err: err}
+			}
+		}
+		if err := decodeValue(member, fv, memberPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unquoteMember returns scalar written in string member of field with string option
+func unquoteMember(member *GoJSON, t reflect.Type) (*GoJSON, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if member.Type == JSONString {
+		value, err := Parse(member.Bytes)
+		if err == nil && (t.Kind() == reflect.String) == (value.Type == JSONString) && value.Type != JSONArray && value.Type != JSONObject {
+			return value, nil
+		}
+	}
+	return nil, fmt.Errorf("string option expects %s quoted in a string, got %s", t, member.Marshal())
+}
+
+func decodeMap(node *GoJSON, v reflect.Value, path []string) error {
+	t := v.Type()
+	kt := t.Key()
+	switch kt.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		if !reflect.PtrTo(kt).Implements(textUnmarshalerType) {
+			return bindErrorf(path, "unsupported map key type %s", kt)
+		}
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(node.Map)))
+	}
+	for _, key := range node.Keys() {
+		memberPath := appendToken(path, key)
+		kv := reflect.New(kt).Elem()
+		var err error
+		switch kind := kt.Kind(); {
+		case kind == reflect.String:
+			kv.SetString(key)
+		case reflect.PtrTo(kt).Implements(textUnmarshalerType):
+			err = kv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key))
+		case kind >= reflect.Int && kind <= reflect.Int64:
+			var i int64
+			if i, err = strconv.ParseInt(key, 10, 64); err == nil && kv.OverflowInt(i) {
+				err = ErrOverflow
+			}
+			kv.SetInt(i)
+		default:
+			var u uint64
+			if u, err = strconv.ParseUint(key, 10, 64); err == nil && kv.OverflowUint(u) {
+				err = ErrOverflow
+			}
+			kv.SetUint(u)
+		}
+		if err != nil {
+			return bindErrorf(memberPath, "invalid map key of type %s: %w", kt, err)
+		}
+		elem := reflect.New(t.Elem()).Elem()
+		if err := decodeValue(node.Map[key], elem, memberPath); err != nil {
+			return err
+		}
+		v.SetMapIndex(kv, elem)
+	}
+	return nil
+}
+
+// interfaceValue returns node as plain Go value
+func interfaceValue(node *GoJSON) interface{} {
+	switch node.Type {
+	case JSONObject:
+		m := make(map[string]interface{}, len(node.Map))
+		for key, value := range node.Map {
+			m[key] = interfaceValue(value)
+		}
+		return m
+	case JSONArray:
+		a := make([]interface{}, len(node.Array))
+		for i, value := range node.Array {
+			a[i] = interfaceValue(value)
+		}
+		return a
+	case JSONString:
+		return string(node.Bytes)
+	case JSONBool:
+		b, _ := node.ValueBool()
+		return b
+	case JSONInt, JSONFloat:
+		if node.Type == JSONInt {
+			if i, err := node.ValueInt64(); err == nil {
+				return i
+			}
+		}
+		f, _ := node.ValueFloat()
+		return f
+	}
+	return nil
+}
+
+// endregion
+
+// region FromValue
+
+// FromValue builds GoJSON from src honouring json struct tags the same way as Decode,
+// struct fields keep their order, map keys are sorted, nil pointers, maps, slices and interfaces become null,
+// time.Time and other encoding.TextMarshaler types become strings, []byte becomes base64 string,
+// floats are written like encoding/json writes them and integral ones are JSONInt,
+// NaN, infinities, channels, functions and complex numbers are returned as *BindError
+func FromValue(src interface{}) (*GoJSON, error) {
+	return encodeValue(reflect.ValueOf(src), nil)
+}
+
+func encodeValue(v reflect.Value, path []string) (*GoJSON, error) {
+	if len(path) > maxBindDepth {
+		return nil, bindErrorf(path, "nesting exceeds %d levels", maxBindDepth)
+	}
+	if !v.IsValid() {
+		return nullNode(), nil
+	}
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nullNode(), nil
+		}
+	}
+	if t == goJSONType {
+		g := v.Interface().(GoJSON)
+		return g.Clone(), nil
+	}
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface {
+		if marshaler, ok := textMarshaler(v); ok {
+			text, err := marshaler.MarshalText()
+			if err != nil {
+				return nil, &BindError{Pointer: FormatPointer(path...), Err: err}
+			}
+			return stringNode(string(text)), nil
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return encodeValue(v.Elem(), path)
+	case reflect.Bool:
+		return &GoJSON{Type: JSONBool, Bytes: strconv.AppendBool(nil, v.Bool())}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &GoJSON{Type: JSONInt, Bytes: strconv.AppendInt(nil, v.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &GoJSON{Type: JSONInt, Bytes: strconv.AppendUint(nil, v.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, bindErrorf(path, "unsupported value %v", f)
+		}
+		return numberText(string(appendFloat(nil, f, t.Bits()))), nil
+	case reflect.String:
+		return stringNode(v.String()), nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && isByteSlice(t) {
+			return stringNode(base64.StdEncoding.EncodeToString(v.Bytes())), nil
+		}
+		array := NewArray()
+		for i := 0; i < v.Len(); i++ {
+			item, err := encodeValue(v.Index(i), appendToken(path, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			array.Set(-1, item)
+		}
+		return array, nil
+	case reflect.Map:
+		return encodeMap(v, path)
+	case reflect.Struct:
+		obj := NewObject()
+		for _, field := range typeFields(t) {
+			fv, ok := fieldByIndex(v, field.index, false)
+			if !ok || field.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+			member, err := encodeValue(fv, appendToken(path, field.name))
+			if err != nil {
+				return nil, err
+			}
+			if field.asString && member.Type != JSONNull {
+				member = stringNode(string(member.Marshal()))
+			}
+			obj.Set(field.name, member)
+		}
+		return obj, nil
+	}
+	return nil, bindErrorf(path, "unsupported type %s", t)
+}
+
+func encodeMap(v reflect.Value, path []string) (*GoJSON, error) {
+	keys := make([]string, v.Len())
+	values := make(map[string]reflect.Value, v.Len())
+	iter := v.MapRange()
+	for i := 0; iter.Next(); i++ {
+		kv := iter.Key()
+		switch kind := kv.Kind(); {
+		case kind == reflect.String:
+			keys[i] = kv.String()
+		case kind >= reflect.Int && kind <= reflect.Int64:
+			keys[i] = strconv.FormatInt(kv.Int(), 10)
+		case kind >= reflect.Uint && kind <= reflect.Uintptr:
+			keys[i] = strconv.FormatUint(kv.Uint(), 10)
+		default:
+			marshaler, ok := textMarshaler(kv)
+			if !ok {
+				return nil, bindErrorf(path, "unsupported map key type %s", kv.Type())
+			}
+			text, err := marshaler.MarshalText()
+			if err != nil {
+				return nil, &BindError{Pointer: FormatPointer(path...), Err: err}
+			}
+			keys[i] = string(text)
+		}
+		values[keys[i]] = iter.Value()
+	}
+	sort.Strings(keys)
+	obj := NewObject()
+	for _, key := range keys {
+		member, err := encodeValue(values[key], appendToken(path, key))
+		if err != nil {
+			return nil, err
+		}
+		obj.Set(key, member)
+	}
+	return obj, nil
+}
+
+// isByteSlice reports slice written as base64 string, bytes implementing encoding.TextMarshaler are written as array
+func isByteSlice(t reflect.Type) bool {
+	return t.Elem().Kind() == reflect.Uint8 && !reflect.PtrTo(t.Elem()).Implements(textMarshalerType)
+}
+
+// textMarshaler returns v or pointer to it as encoding.TextMarshaler
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if v.Type().Implements(textMarshalerType) {
+		return v.Interface().(encoding.TextMarshaler), true
+	}
+	if v.CanAddr() && reflect.PtrTo(v.Type()).Implements(textMarshalerType) {
+		return v.Addr().Interface().(encoding.TextMarshaler), true
+	}
+	return nil, false
+}
+
+// appendFloat formats float like encoding/json: exponent is used only for very small and very large numbers
+func appendFloat(dst []byte, f float64, bits int) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst
+}
+
+// isEmptyValue reports values skipped by omitempty option
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// endregion
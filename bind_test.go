@@ -0,0 +1,229 @@
+package gojson
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type bindBase struct {
+	ID      int64 `json:"id"`
+	Created time.Time
+	Note    string `json:"note,omitempty"`
+}
+
+type bindItem struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price,string"`
+	Qty   *int    `json:"qty"`
+}
+
+type bindOrder struct {
+	bindBase
+	*Extra
+	Items   []bindItem            `json:"items"`
+	Tags    map[string]int        `json:"tags,omitempty"`
+	Hosts   map[netip.Addr]string `json:"hosts,omitempty"`
+	Raw     *GoJSON               `json:"raw"`
+	Any     interface{}           `json:"any"`
+	Flags   [2]bool               `json:"flags"`
+	Skip    string                `json:"-"`
+	private int
+}
+
+type Extra struct {
+	Coupon string `json:"coupon,omitempty"`
+}
+
+func TestDecode(t *testing.T) {
+	json := Unmarshal([]byte(`{"id": 7, "Created": "2024-05-01T10:00:00Z", "items": [{"name": "a", "price": "2.5", "qty": 3}, {"name": "b", "price": "1", "qty": null}],
+		"tags": {"x": 1}, "hosts": {"10.0.0.1": "db"}, "raw": {"k": [1]}, "any": {"n": 1, "f": 1.5, "l": [true, null, "s"]},
+		"flags": [true], "coupon": "SALE", "-": "skip", "NOTE": "case", "unknown": 1}`))
+	order := bindOrder{Skip: "kept", Flags: [2]bool{true, true}}
+	if err := Decode(json, &order); err != nil {
+		t.Fatal(err)
+	}
+	qty := 3
+	expected := bindOrder{
+		bindBase: bindBase{ID: 7, Created: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), Note: "case"},
+		Extra:    &Extra{Coupon: "SALE"},
+		Items:    []bindItem{{Name: "a", Price: 2.5, Qty: &qty}, {Name: "b", Price: 1}},
+		Tags:     map[string]int{"x": 1},
+		Hosts:    map[netip.Addr]string{},
+		Any:      map[string]interface{}{"n": int64(1), "f": 1.5, "l": []interface{}{true, nil, "s"}},
+		Flags:    [2]bool{true, false},
+		Skip:     "kept",
+	}
+	raw, hosts := order.Raw, order.Hosts
+	order.Raw, order.Hosts = nil, map[netip.Addr]string{}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("unexpected result %+v", order)
+	}
+	if string(raw.Marshal()) != `{"k":[1]}` || raw == json.Get("raw") || raw.Get("k").parent != raw {
+		t.Fatal("raw node is expected to be a copy", string(raw.Marshal()))
+	}
+	for ip, name := range hosts {
+		if ip.String() != "10.0.0.1" || name != "db" {
+			t.Fatal("unexpected hosts", hosts)
+		}
+	}
+
+	errs := map[string]string{
+		`{"id": "1"}`:                   `/id: cannot decode string into int64`,
+		`{"id": 1.5}`:                   `/id: cannot decode 1.5 into int64: number is not an integer`,
+		`{"items": [{}, {"price": 1}]}`: `/items/1/price: string option expects float64 quoted in a string, got 1`,
+		`{"items": [{"qty": 1e30}]}`:    `/items/0/qty: cannot decode 1e30 into int: number overflows requested type`,
+		`{"Created": "yesterday"}`:      `/Created: parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`,
+		`{"tags": {"a": {"b": 1}}}`:     `/tags/a: cannot decode object into int`,
+		`{"hosts": {"x": "y"}}`:         `/hosts/x: invalid map key of type netip.Addr: ParseAddr("x"): unable to parse IP`,
+		`[]`:                            `cannot decode array into gojson.bindOrder`,
+		`{"flags": [true, false, 1]}`:   ``,
+	}
+	for input, expected := range errs {
+		err := Decode(Unmarshal([]byte(input)), &bindOrder{})
+		if expected == "" {
+			if err != nil {
+				t.Fatal(input, err)
+			}
+			continue
+		}
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) || err.Error() != expected {
+			t.Fatalf("%s: expected error %q, got %v", input, expected, err)
+		}
+	}
+	if err := Decode(json, order); err == nil {
+		t.Fatal("expected error for non-pointer destination")
+	}
+
+	var small int8
+	if err := Decode(Unmarshal([]byte(`300`)), &small); !errors.Is(err, ErrOverflow) {
+		t.Fatal("expected overflow", err)
+	}
+	m := map[int]*bindItem{}
+	if err := Decode(Unmarshal([]byte(`{"1": {"name": "x", "price": "3"}, "2": null}`)), &m); err != nil || m[1].Name != "x" || m[1].Price != 3 || m[2] != nil || len(m) != 2 {
+		t.Fatal("unexpected map", m, err)
+	}
+}
+
+func TestFromValue(t *testing.T) {
+	qty := 2
+	order := bindOrder{
+		bindBase: bindBase{ID: 1, Created: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		Items:    []bindItem{{Name: "a\"<", Price: 1e21, Qty: &qty}, {Name: "b", Price: 0.5}},
+		Tags:     map[string]int{"b": 2, "a": 1},
+		Hosts:    map[netip.Addr]string{netip.MustParseAddr("10.0.0.1"): "db"},
+		Raw:      Unmarshal([]byte(`{"k": [1]}`)),
+		Any:      []interface{}{uint8(1), float32(0.1), nil},
+		Skip:     "skip",
+	}
+	json, err := FromValue(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"id":1,"Created":"2024-05-01T10:00:00Z","items":[{"name":"a\"<","price":"1e+21","qty":2},{"name":"b","price":"0.5","qty":null}],` +
+		`"tags":{"a":1,"b":2},"hosts":{"10.0.0.1":"db"},"raw":{"k":[1]},"any":[1,0.1,null],"flags":[false,false]}`
+	if got := string(json.Marshal()); got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+	if json.Get("raw") == order.Raw || json.Get("items").Get(0).parent != json.Get("items") {
+		t.Fatal("nodes are expected to be copied and attached")
+	}
+
+	var decoded bindOrder
+	if err = Decode(json, &decoded); err != nil || decoded.Items[0].Price != 1e21 || *decoded.Items[0].Qty != 2 || !decoded.Created.Equal(order.Created) {
+		t.Fatal("round trip failed", decoded, err)
+	}
+
+	if json, _ = FromValue(nil); json.Type != JSONNull {
+		t.Fatal("expected null")
+	}
+	type cyclic struct {
+		Next *cyclic `json:"next"`
+	}
+	loop := &cyclic{}
+	loop.Next = loop
+	for value, message := range map[interface{}]string{
+		&struct{ F []float64 }{[]float64{0, 1 / zero()}}: `/F/1: unsupported value +Inf`,
+		struct{ C chan int }{}:                           `/C: unsupported type chan int`,
+		loop:                                             `nesting exceeds 1000 levels`,
+	} {
+		if _, err := FromValue(value); err == nil || err.Error()[len(err.Error())-len(message):] != message {
+			t.Fatalf("expected error %q, got %v", message, err)
+		}
+	}
+}
+
+func TestFromValue_Float(t *testing.T) {
+	for _, value := range []interface{}{
+		0.0, -0.0, 1.0, -2.5, 0.1, 1e-6, 1e-7, -1.5e-9, 123456789.125, 1e20, 1e21, -3e300, 5e-324,
+		float32(0.1), float32(1e-7), float32(3.4e38), float32(1e20), float32(1e21),
+	} {
+		expected, _ := json.Marshal(value)
+		if node, err := FromValue(value); err != nil || string(node.Bytes) != string(expected) {
+			t.Fatalf("expected %s for %v, got %v %v", expected, value, node, err)
+		}
+	}
+}
+
+func TestFromValue_EncodingJSON(t *testing.T) {
+	type value struct {
+		Data   []byte   `json:"data"`
+		None   []byte   `json:"none"`
+		Count  *int     `json:"count,string"`
+		Ratio  *float64 `json:"ratio,string"`
+		Absent *bool    `json:"absent,string"`
+		Float  float64  `json:"float"`
+	}
+	count, ratio := 3, 0.5
+	src := value{Data: []byte("hi\x00\xff"), Count: &count, Ratio: &ratio, Float: 2}
+	expected, _ := json.Marshal(src)
+	node, err := FromValue(src)
+	if err != nil || string(node.Marshal()) != string(expected) {
+		t.Fatalf("expected %s, got %s %v", expected, node.Marshal(), err)
+	}
+	if !Equal(node.Get("float"), Unmarshal([]byte(`2`))) || node.Get("float").Type != JSONInt {
+		t.Fatal("integral float expected to be equal to parsed integer")
+	}
+	var decoded value
+	if err = Decode(Unmarshal(expected), &decoded); err != nil || !reflect.DeepEqual(decoded, src) {
+		t.Fatalf("expected %+v, got %+v %v", src, decoded, err)
+	}
+	if err = Decode(Unmarshal([]byte(`{"data": [104, 105]}`)), &decoded); err != nil || string(decoded.Data) != "hi" {
+		t.Fatalf("expected bytes decoded from array, got %q %v", decoded.Data, err)
+	}
+	message := `/data: cannot decode base64 string into []uint8: illegal base64 data at input byte 0`
+	if err = Decode(Unmarshal([]byte(`{"data": "!"}`)), &decoded); err == nil || err.Error() != message {
+		t.Fatalf("expected error %q, got %v", message, err)
+	}
+}
+
+func zero() float64 {
+	return 0
+}
+
+func TestTypeFields(t *testing.T) {
+	type A struct {
+		Name string
+		X    int `json:"x"`
+	}
+	type B struct {
+		Name string
+		X    int
+	}
+	type C struct {
+		A
+		B
+		Name string `json:"name"`
+	}
+	var names []string
+	for _, f := range typeFields(reflect.TypeOf(C{})) {
+		names = append(names, f.name)
+	}
+	if !reflect.DeepEqual(names, []string{"x", "X", "name"}) {
+		t.Fatal("unexpected fields", names)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/FanFani4/gojson"
+)
+
+// options of the generated code
+type options struct {
+	pkg       string // package name
+	root      string // name of the root type
+	accessors bool   // generate typed accessors reading *gojson.GoJSON
+}
+
+// initialisms are written in upper case in Go names, like golint suggests
+var initialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true, "TCP": true, "TTL": true, "UDP": true,
+	"UI": true, "URI": true, "URL": true, "UTF8": true, "UUID": true, "XML": true,
+}
+
+// goType is Go type of values at one place of the samples
+type goType struct {
+	expr     string  // type expression
+	kind     string  // string, int64, float64, bool, struct, slice, map or any
+	nullable bool    // expr is a pointer
+	elem     *goType // element of slice
+	name     string  // name of struct
+}
+
+// structType is struct waiting to be generated
+type structType struct {
+	name   string
+	schema *gojson.GoJSON
+}
+
+type field struct {
+	name, key string
+	typ       *goType
+	optional  bool
+}
+
+type generator struct {
+	options
+	names map[string]bool
+	queue []structType
+	buf   bytes.Buffer
+}
+
+// generate returns formatted Go source with types describing all samples
+func generate(opts options, samples ...*gojson.GoJSON) ([]byte, error) {
+	g := &generator{options: opts, names: make(map[string]bool)}
+	schema := gojson.InferSchema(samples...)
+	fmt.Fprintf(&g.buf, "// Code generated by gojson-gen. DO NOT EDIT.\n\npackage %s\n", g.pkg)
+	if g.accessors {
+		g.buf.WriteString("\nimport \"github.com/FanFani4/gojson\"\n")
+	}
+	root := g.resolve(schema, g.root)
+	if root.kind != "struct" || root.name != g.root {
+		g.names[g.root] = true
+		fmt.Fprintf(&g.buf, "\n// %s is generated from JSON samples\ntype %s %s\n", g.root, g.root, root.expr)
+	}
+	for len(g.queue) > 0 {
+		next := g.queue[0]
+		g.queue = g.queue[1:]
+		g.writeStruct(next)
+	}
+	return format.Source(g.buf.Bytes())
+}
+
+// resolve returns Go type of values described by inferred schema, name is used for new structs
+func (g *generator) resolve(schema *gojson.GoJSON, name string) *goType {
+	typ := schema.Get("type")
+	names := typ.Array
+	if typ.Type == gojson.JSONString {
+		names = []*gojson.GoJSON{typ}
+	}
+	var types []string
+	nullable := false
+	for _, item := range names {
+		if s := string(item.Bytes); s == "null" {
+			nullable = true
+		} else {
+			types = append(types, s)
+		}
+	}
+	if len(types) != 1 {
+		return &goType{expr: "interface{}", kind: "any"}
+	}
+	t := &goType{}
+	switch types[0] {
+	case "string":
+		t.expr, t.kind = "string", "string"
+	case "integer":
+		t.expr, t.kind = "int64", "int64"
+	case "number":
+		t.expr, t.kind = "float64", "float64"
+	case "boolean":
+		t.expr, t.kind = "bool", "bool"
+	case "array":
+		t.kind = "slice"
+		t.elem = &goType{expr: "interface{}", kind: "any"}
+		if items := schema.Get("items"); items.Type == gojson.JSONObject {
+			itemName := singular(name)
+			if itemName == name {
+				itemName += "Item"
+			}
+			t.elem = g.resolve(items, itemName)
+		}
+		t.expr = "[]" + t.elem.expr
+		return t
+	case "object":
+		if properties := schema.Get("properties"); properties.Len() == 0 {
+			t.expr, t.kind = "map[string]interface{}", "map"
+			return t
+		}
+		t.kind, t.name = "struct", g.uniqueName(name)
+		t.expr = t.name
+		g.queue = append(g.queue, structType{name: t.name, schema: schema})
+	}
+	if nullable {
+		t.expr, t.nullable = "*"+t.expr, true
+	}
+	return t
+}
+
+// uniqueName returns name that isn't used by another generated type,
+// with accessors the name of its Node wrapper is taken as well
+func (g *generator) uniqueName(name string) string {
+	unique := name
+	for i := 2; g.names[unique] || g.accessors && g.names[unique+"Node"]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	if g.accessors {
+		g.names[unique+"Node"] = true
+	}
+	return unique
+}
+
+func (g *generator) writeStruct(s structType) {
+	required := make(map[string]bool)
+	for _, key := range s.schema.Get("required").Array {
+		required[string(key.Bytes)] = true
+	}
+	properties := s.schema.Get("properties")
+	var fields []field
+	used := make(map[string]bool)
+	for _, key := range properties.Keys() {
+		name := goName(key)
+		for i := 2; used[name]; i++ {
+			name = goName(key) + strconv.Itoa(i)
+		}
+		used[name] = true
+		fields = append(fields, field{name: name, key: key, typ: g.resolve(properties.Get(key), name), optional: !required[key]})
+	}
+
+	fmt.Fprintf(&g.buf, "\n// %s is generated from JSON samples\ntype %s struct {\n", s.name, s.name)
+	for _, f := range fields {
+		tag := f.key
+		if f.optional {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&g.buf, "\t%s %s `json:%s`\n", f.name, f.typ.expr, strconv.Quote(tag))
+	}
+	g.buf.WriteString("}\n")
+	if g.accessors {
+		g.writeAccessors(s.name, fields)
+	}
+}
+
+// reservedNames are promoted from embedded *gojson.GoJSON, accessors with these names get "Field" suffix
+var reservedNames = func() map[string]bool {
+	names := map[string]bool{"GoJSON": true}
+	t := reflect.TypeOf(&gojson.GoJSON{})
+	for i := 0; i < t.NumMethod(); i++ {
+		names[t.Method(i).Name] = true
+	}
+	for i := 0; i < t.Elem().NumField(); i++ {
+		names[t.Elem().Field(i).Name] = true
+	}
+	return names
+}()
+
+// accessorNames returns names of accessor methods of fields that don't hide promoted fields and methods
+func accessorNames(fields []field) []string {
+	used := make(map[string]bool)
+	for _, f := range fields {
+		used[f.name] = true
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+		if reservedNames[f.name] {
+			names[i] = f.name + "Field"
+			for n := 2; used[names[i]] || reservedNames[names[i]]; n++ {
+				names[i] = f.name + "Field" + strconv.Itoa(n)
+			}
+			used[names[i]] = true
+		}
+	}
+	return names
+}
+
+// getters are gojson getters of scalar kinds
+var getters = map[string]string{"string": "ValueString", "int64": "ValueInt64", "float64": "ValueFloat", "bool": "ValueBool"}
+
+// writeAccessors writes wrapper of *gojson.GoJSON with a method reading every field
+func (g *generator) writeAccessors(name string, fields []field) {
+	node := name + "Node"
+	fmt.Fprintf(&g.buf, "\n// %s reads %s fields from parsed document without decoding it\ntype %s struct {\n\t*gojson.GoJSON\n}\n", node, name, node)
+	methods := accessorNames(fields)
+	for i, f := range fields {
+		method := methods[i]
+		get := fmt.Sprintf("n.GoJSON.Get(%s)", strconv.Quote(f.key))
+		fmt.Fprintf(&g.buf, "\n// %s returns %q member\n", method, f.key)
+		t := f.typ
+		getter := getters[strings.TrimPrefix(t.expr, "*")]
+		switch {
+		case t.kind == "struct":
+			fmt.Fprintf(&g.buf, "func (n %s) %s() %sNode {\n\treturn %sNode{%s}\n}\n", node, method, t.name, t.name, get)
+		case getter != "" && t.nullable:
+			fmt.Fprintf(&g.buf, "func (n %s) %s() (%s, error) {\n\tvalue := %s\n", node, method, t.expr, get)
+			fmt.Fprintf(&g.buf, "\tif value.Type == gojson.JSONNull || value.Type == gojson.JSONInvalid {\n\t\treturn nil, nil\n\t}\n")
+			fmt.Fprintf(&g.buf, "\tresult, err := value.%s()\n\treturn &result, err\n}\n", getter)
+		case getter != "":
+			fmt.Fprintf(&g.buf, "func (n %s) %s() (%s, error) {\n\treturn %s.%s()\n}\n", node, method, t.expr, get, getter)
+		case t.kind == "slice" && t.elem.kind == "struct" && !t.elem.nullable:
+			fmt.Fprintf(&g.buf, "func (n %s) %s() []%sNode {\n\titems := %s.Array\n", node, method, t.elem.name, get)
+			fmt.Fprintf(&g.buf, "\tnodes := make([]%sNode, len(items))\n\tfor i, item := range items {\n", t.elem.name)
+			fmt.Fprintf(&g.buf, "\t\tnodes[i] = %sNode{item}\n\t}\n\treturn nodes\n}\n", t.elem.name)
+		case t.kind == "slice" && getters[t.elem.expr] != "":
+			fmt.Fprintf(&g.buf, "func (n %s) %s() (%s, error) {\n\titems := %s.Array\n", node, method, t.expr, get)
+			fmt.Fprintf(&g.buf, "\tvalues := make(%s, len(items))\n\tfor i, item := range items {\n\t\tvar err error\n", t.expr)
+			fmt.Fprintf(&g.buf, "\t\tif values[i], err = item.%s(); err != nil {\n\t\t\treturn nil, err\n\t\t}\n\t}\n", getters[t.elem.expr])
+			g.buf.WriteString("\treturn values, nil\n}\n")
+		default:
+			fmt.Fprintf(&g.buf, "func (n %s) %s() *gojson.GoJSON {\n\treturn %s\n}\n", node, method, get)
+		}
+	}
+}
+
+// goName converts json key like "user_id" or "createdAt" into exported Go name like UserID or CreatedAt
+func goName(key string) string {
+	parts := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, part := range parts {
+		if upper := strings.ToUpper(part); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	name := b.String()
+	switch {
+	case name == "":
+		return "Field"
+	case unicode.IsDigit([]rune(name)[0]):
+		return "F" + name
+	}
+	return name
+}
+
+// singular returns name of array element type, "Items" becomes "Item"
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && len(name) > 1:
+		return name[:len(name)-1]
+	}
+	return name
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FanFani4/gojson"
+)
+
+func TestGenerate(t *testing.T) {
+	samples := []*gojson.GoJSON{
+		gojson.Unmarshal([]byte(`{"user_id": 1, "price": 2.5, "tags": ["a"], "address": {"city": "X"}, "items": [{"qty": 1}], "note": "n"}`)),
+		gojson.Unmarshal([]byte(`{"user_id": 2, "price": 3, "tags": [], "address": null, "items": [], "note": null}`)),
+	}
+	src, err := generate(options{pkg: "models", root: "Order"}, samples...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `// Code generated by gojson-gen. DO NOT EDIT.
+
+package models
+
+// Order is generated from JSON samples
+type Order struct {
+	UserID  int64    ` + "`json:\"user_id\"`" + `
+	Price   float64  ` + "`json:\"price\"`" + `
+	Tags    []string ` + "`json:\"tags\"`" + `
+	Address *Address ` + "`json:\"address\"`" + `
+	Items   []Item   ` + "`json:\"items\"`" + `
+	Note    *string  ` + "`json:\"note\"`" + `
+}
+
+// Address is generated from JSON samples
+type Address struct {
+	City string ` + "`json:\"city\"`" + `
+}
+
+// Item is generated from JSON samples
+type Item struct {
+	Qty int64 ` + "`json:\"qty\"`" + `
+}
+`
+	if string(src) != expected {
+		t.Fatalf("unexpected source:\n%s", src)
+	}
+
+	src, err = generate(options{pkg: "models", root: "Order", accessors: true}, samples...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`import "github.com/FanFani4/gojson"`,
+		"type OrderNode struct {\n\t*gojson.GoJSON\n}",
+		"func (n OrderNode) UserID() (int64, error) {\n\treturn n.GoJSON.Get(\"user_id\").ValueInt64()\n}",
+		"func (n OrderNode) Address() AddressNode {",
+		"func (n OrderNode) Items() []ItemNode {",
+		"func (n OrderNode) Tags() ([]string, error) {",
+		"func (n OrderNode) Note() (*string, error) {",
+		"func (n ItemNode) Qty() (int64, error) {",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("expected %q in\n%s", s, src)
+		}
+	}
+
+	src, _ = generate(options{pkg: "main", root: "Root"}, gojson.Unmarshal([]byte(`[{"a": 1, "b": true}, {"a": 1.5}]`)))
+	if !strings.Contains(string(src), "type Root []RootItem") ||
+		!strings.Contains(string(src), "A float64 `json:\"a\"`\n\tB bool    `json:\"b,omitempty\"`") {
+		t.Fatalf("unexpected source:\n%s", src)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	names := map[string]string{"user_id": "UserID", "createdAt": "CreatedAt", "api-url": "APIURL", "1st": "F1st", "$": "Field", "Items": "Items"}
+	for key, name := range names {
+		if got := goName(key); got != name {
+			t.Fatalf("%s: expected %s, got %s", key, name, got)
+		}
+	}
+	if singular("Categories") != "Category" || singular("Items") != "Item" || singular("Address") != "Address" {
+		t.Fatal("unexpected singular names")
+	}
+}
+
+func TestGenerate_Compiles(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool is not available")
+	}
+	sample := gojson.Unmarshal([]byte(`{"GoJSON": 1, "get": "x", "Len": [1.5], "keys": {"Set": true}, "Keys": null, "KeysField": 2,
+		"items": [{"Map": "m", "Type": 1}], "any": [1, "a"], "Array": {}, "user": {"a": 1}, "userNode": {"b": 2}}`))
+	src, err := generate(options{pkg: "accessors", root: "Root", accessors: true}, sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"func (n RootNode) GoJSONField() (int64, error)", "func (n RootNode) GetField() (string, error)",
+		"func (n RootNode) LenField() ([]float64, error)", "func (n KeysNode) SetField() (bool, error)",
+		"func (n RootNode) KeysField2() KeysNode", "func (n RootNode) Keys2() *gojson.GoJSON", "func (n RootNode) ArrayField() *gojson.GoJSON", "func (n RootNode) KeysField() (int64, error)", "func (n ItemNode) MapField() (string, error)",
+		"func (n RootNode) User() UserNode", "func (n RootNode) UserNode() UserNode2Node", "type UserNode2 struct"} {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("expected %q in\n%s", s, src)
+		}
+	}
+
+	// directories starting with _ are ignored by ./... patterns
+	dir, err := os.MkdirTemp(".", "_accessors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "root.go"), src, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command(goTool, "vet", "./"+filepath.ToSlash(dir)).CombinedOutput(); err != nil {
+		t.Fatalf("generated code doesn't compile: %v\n%s\n%s", err, out, src)
+	}
+}
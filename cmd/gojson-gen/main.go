@@ -0,0 +1,67 @@
+// Command gojson-gen reads JSON samples and writes Go struct definitions describing them
+//
+//	gojson-gen [-package name] [-type Root] [-accessors] [-o file.go] sample.json...
+//
+// samples are read from stdin if no files are given, fields missing in some samples get omitempty,
+// fields that are sometimes null become pointers, numbers are int64 unless some sample has a fraction or exponent,
+// with -accessors every struct gets a wrapper of *gojson.GoJSON with typed getters that read the parsed document directly
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/FanFani4/gojson"
+)
+
+func main() {
+	opts := options{}
+	flag.StringVar(&opts.pkg, "package", "main", "package name of the generated file")
+	flag.StringVar(&opts.root, "type", "Root", "name of the root type")
+	flag.BoolVar(&opts.accessors, "accessors", false, "generate typed accessors reading *gojson.GoJSON")
+	output := flag.String("o", "", "output file, stdout by default")
+	flag.Parse()
+
+	var samples []*gojson.GoJSON
+	read := func(name string, r io.Reader) {
+		data, err := io.ReadAll(r)
+		if err == nil {
+			var sample *gojson.GoJSON
+			if sample, err = gojson.Parse(data); err == nil {
+				samples = append(samples, sample)
+				return
+			}
+		}
+		fmt.Fprintf(os.Stderr, "gojson-gen: %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	if flag.NArg() == 0 {
+		read("stdin", os.Stdin)
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gojson-gen: %v\n", err)
+			os.Exit(1)
+		}
+		read(name, f)
+		f.Close()
+	}
+
+	src, err := generate(opts, samples...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gojson-gen: %v\n", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*output, src, 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gojson-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,44 @@
+// Package example shows types with methods generated by gojson-marshal
+package example
+
+import "time"
+
+//go:generate go run github.com/FanFani4/gojson/cmd/gojson-marshal
+
+// Status of the order
+type Status string
+
+// Order is a typical API payload
+//
+//gojson:generate
+type Order struct {
+	ID       int64             `json:"id"`
+	Status   Status            `json:"status"`
+	Customer *Customer         `json:"customer"`
+	Items    []Item            `json:"items"`
+	Total    float64           `json:"total"`
+	Paid     bool              `json:"paid"`
+	Created  time.Time         `json:"created"`
+	Coupon   *string           `json:"coupon,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Meta     map[string]string `json:"meta,omitempty"`
+	Internal string            `json:"-"`
+}
+
+// Customer placed the order
+//
+//gojson:generate
+type Customer struct {
+	Email string `json:"email,omitempty"`
+	Name  string `json:"name"`
+}
+
+// Item is a line of the order
+//
+//gojson:generate
+type Item struct {
+	SKU      string  `json:"sku"`
+	Quantity uint16  `json:"qty"`
+	Price    float32 `json:"price"`
+	Options  [][]int `json:"options,omitempty"`
+}
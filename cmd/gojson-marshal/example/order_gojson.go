@@ -0,0 +1,385 @@
+// Code generated by gojson-marshal. DO NOT EDIT.
+
+package example
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/FanFani4/gojson"
+)
+
+// MarshalGoJSON writes v as json object to buf
+func (v *Order) MarshalGoJSON(buf *bytes.Buffer) {
+	if v == nil {
+		buf.WriteString("null")
+		return
+	}
+	var scratch [64]byte
+	buf.WriteByte('{')
+	buf.WriteString(`"id":`)
+	buf.Write(strconv.AppendInt(scratch[:0], v.ID, 10))
+	buf.WriteString(`,"status":`)
+	gojson.WriteString(buf, string(v.Status))
+	buf.WriteString(`,"customer":`)
+	v.Customer.MarshalGoJSON(buf)
+	buf.WriteString(`,"items":`)
+	if v.Items == nil {
+		buf.WriteString("null")
+	} else {
+		buf.WriteByte('[')
+		for i1 := range v.Items {
+			if i1 > 0 {
+				buf.WriteByte(',')
+			}
+			v.Items[i1].MarshalGoJSON(buf)
+		}
+		buf.WriteByte(']')
+	}
+	buf.WriteString(`,"total":`)
+	buf.Write(gojson.AppendFloat(scratch[:0], v.Total, 64))
+	buf.WriteString(`,"paid":`)
+	buf.WriteString(strconv.FormatBool(v.Paid))
+	buf.WriteString(`,"created":`)
+	gojson.WriteString(buf, v.Created.Format(time.RFC3339Nano))
+	if v.Coupon != nil {
+		buf.WriteString(`,"coupon":`)
+		gojson.WriteString(buf, *v.Coupon)
+	}
+	if len(v.Tags) != 0 {
+		buf.WriteString(`,"tags":`)
+		buf.WriteByte('[')
+		for i1 := range v.Tags {
+			if i1 > 0 {
+				buf.WriteByte(',')
+			}
+			gojson.WriteString(buf, v.Tags[i1])
+		}
+		buf.WriteByte(']')
+	}
+	if len(v.Meta) != 0 {
+		buf.WriteString(`,"meta":`)
+		keys1 := make([]string, 0, len(v.Meta))
+		for key := range v.Meta {
+			keys1 = append(keys1, key)
+		}
+		sort.Strings(keys1)
+		buf.WriteByte('{')
+		for i1, key := range keys1 {
+			if i1 > 0 {
+				buf.WriteByte(',')
+			}
+			gojson.WriteString(buf, key)
+			buf.WriteByte(':')
+			elem1 := v.Meta[key]
+			gojson.WriteString(buf, elem1)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('}')
+}
+
+// UnmarshalGoJSON sets fields of v from json object node, members without field are ignored
+func (v *Order) UnmarshalGoJSON(node *gojson.GoJSON) error {
+	if node.Type != gojson.JSONObject {
+		return errors.New("Order: expected object")
+	}
+	if value, ok := node.Map["id"]; ok {
+		if value.Type != gojson.JSONNull {
+			n, err := value.ValueInt64()
+			if err != nil {
+				return fmt.Errorf("id: %w", err)
+			}
+			v.ID = n
+		}
+	}
+	if value, ok := node.Map["status"]; ok {
+		if value.Type != gojson.JSONNull {
+			if value.Type != gojson.JSONString {
+				return errors.New("status: expected string")
+			}
+			s, _ := value.ValueString()
+			v.Status = Status(s)
+		}
+	}
+	if value, ok := node.Map["customer"]; ok {
+		if value.Type == gojson.JSONNull {
+			v.Customer = nil
+		} else {
+			if v.Customer == nil {
+				v.Customer = new(Customer)
+			}
+			if err := v.Customer.UnmarshalGoJSON(value); err != nil {
+				return fmt.Errorf("customer: %w", err)
+			}
+		}
+	}
+	if value, ok := node.Map["items"]; ok {
+		if value.Type == gojson.JSONNull {
+			v.Items = nil
+		} else {
+			if value.Type != gojson.JSONArray {
+				return errors.New("items: expected array")
+			}
+			items1 := make([]Item, len(value.Array))
+			for i1, item1 := range value.Array {
+				if item1.Type != gojson.JSONNull {
+					if err := items1[i1].UnmarshalGoJSON(item1); err != nil {
+						return fmt.Errorf("items[%d]: %w", i1, err)
+					}
+				}
+			}
+			v.Items = items1
+		}
+	}
+	if value, ok := node.Map["total"]; ok {
+		if value.Type != gojson.JSONNull {
+			f, err := value.ValueFloat()
+			if err != nil {
+				return fmt.Errorf("total: %w", err)
+			}
+			v.Total = f
+		}
+	}
+	if value, ok := node.Map["paid"]; ok {
+		if value.Type != gojson.JSONNull {
+			b, err := value.ValueBool()
+			if err != nil {
+				return fmt.Errorf("paid: %w", err)
+			}
+			v.Paid = b
+		}
+	}
+	if value, ok := node.Map["created"]; ok {
+		if value.Type != gojson.JSONNull {
+			if value.Type != gojson.JSONString {
+				return errors.New("created: expected string")
+			}
+			s, _ := value.ValueString()
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return fmt.Errorf("created: %w", err)
+			}
+			v.Created = t
+		}
+	}
+	if value, ok := node.Map["coupon"]; ok {
+		if value.Type == gojson.JSONNull {
+			v.Coupon = nil
+		} else {
+			if v.Coupon == nil {
+				v.Coupon = new(string)
+			}
+			if value.Type != gojson.JSONString {
+				return errors.New("coupon: expected string")
+			}
+			s, _ := value.ValueString()
+			*v.Coupon = s
+		}
+	}
+	if value, ok := node.Map["tags"]; ok {
+		if value.Type == gojson.JSONNull {
+			v.Tags = nil
+		} else {
+			if value.Type != gojson.JSONArray {
+				return errors.New("tags: expected array")
+			}
+			items1 := make([]string, len(value.Array))
+			for i1, item1 := range value.Array {
+				if item1.Type != gojson.JSONNull {
+					if item1.Type != gojson.JSONString {
+						return fmt.Errorf("tags[%d]: expected string", i1)
+					}
+					s, _ := item1.ValueString()
+					items1[i1] = s
+				}
+			}
+			v.Tags = items1
+		}
+	}
+	if value, ok := node.Map["meta"]; ok {
+		if value.Type == gojson.JSONNull {
+			v.Meta = nil
+		} else {
+			if value.Type != gojson.JSONObject {
+				return errors.New("meta: expected object")
+			}
+			m1 := make(map[string]string, len(value.Map))
+			for key1, item1 := range value.Map {
+				var elem1 string
+				if item1.Type != gojson.JSONNull {
+					if item1.Type != gojson.JSONString {
+						return fmt.Errorf("meta[%q]: expected string", key1)
+					}
+					s, _ := item1.ValueString()
+					elem1 = s
+				}
+				m1[key1] = elem1
+			}
+			v.Meta = m1
+		}
+	}
+	return nil
+}
+
+// MarshalGoJSON writes v as json object to buf
+func (v *Customer) MarshalGoJSON(buf *bytes.Buffer) {
+	if v == nil {
+		buf.WriteString("null")
+		return
+	}
+	comma := false
+	buf.WriteByte('{')
+	if v.Email != "" {
+		buf.WriteString(`"email":`)
+		gojson.WriteString(buf, v.Email)
+		comma = true
+	}
+	if comma {
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"name":`)
+	gojson.WriteString(buf, v.Name)
+	buf.WriteByte('}')
+}
+
+// UnmarshalGoJSON sets fields of v from json object node, members without field are ignored
+func (v *Customer) UnmarshalGoJSON(node *gojson.GoJSON) error {
+	if node.Type != gojson.JSONObject {
+		return errors.New("Customer: expected object")
+	}
+	if value, ok := node.Map["email"]; ok {
+		if value.Type != gojson.JSONNull {
+			if value.Type != gojson.JSONString {
+				return errors.New("email: expected string")
+			}
+			s, _ := value.ValueString()
+			v.Email = s
+		}
+	}
+	if value, ok := node.Map["name"]; ok {
+		if value.Type != gojson.JSONNull {
+			if value.Type != gojson.JSONString {
+				return errors.New("name: expected string")
+			}
+			s, _ := value.ValueString()
+			v.Name = s
+		}
+	}
+	return nil
+}
+
+// MarshalGoJSON writes v as json object to buf
+func (v *Item) MarshalGoJSON(buf *bytes.Buffer) {
+	if v == nil {
+		buf.WriteString("null")
+		return
+	}
+	var scratch [64]byte
+	buf.WriteByte('{')
+	buf.WriteString(`"sku":`)
+	gojson.WriteString(buf, v.SKU)
+	buf.WriteString(`,"qty":`)
+	buf.Write(strconv.AppendUint(scratch[:0], uint64(v.Quantity), 10))
+	buf.WriteString(`,"price":`)
+	buf.Write(gojson.AppendFloat(scratch[:0], float64(v.Price), 32))
+	if len(v.Options) != 0 {
+		buf.WriteString(`,"options":`)
+		buf.WriteByte('[')
+		for i1 := range v.Options {
+			if i1 > 0 {
+				buf.WriteByte(',')
+			}
+			if v.Options[i1] == nil {
+				buf.WriteString("null")
+			} else {
+				buf.WriteByte('[')
+				for i2 := range v.Options[i1] {
+					if i2 > 0 {
+						buf.WriteByte(',')
+					}
+					buf.Write(strconv.AppendInt(scratch[:0], int64(v.Options[i1][i2]), 10))
+				}
+				buf.WriteByte(']')
+			}
+		}
+		buf.WriteByte(']')
+	}
+	buf.WriteByte('}')
+}
+
+// UnmarshalGoJSON sets fields of v from json object node, members without field are ignored
+func (v *Item) UnmarshalGoJSON(node *gojson.GoJSON) error {
+	if node.Type != gojson.JSONObject {
+		return errors.New("Item: expected object")
+	}
+	if value, ok := node.Map["sku"]; ok {
+		if value.Type != gojson.JSONNull {
+			if value.Type != gojson.JSONString {
+				return errors.New("sku: expected string")
+			}
+			s, _ := value.ValueString()
+			v.SKU = s
+		}
+	}
+	if value, ok := node.Map["qty"]; ok {
+		if value.Type != gojson.JSONNull {
+			n, err := value.ValueUint64()
+			if err != nil {
+				return fmt.Errorf("qty: %w", err)
+			}
+			if uint64(uint16(n)) != n {
+				return fmt.Errorf("qty: %w", gojson.ErrOverflow)
+			}
+			v.Quantity = uint16(n)
+		}
+	}
+	if value, ok := node.Map["price"]; ok {
+		if value.Type != gojson.JSONNull {
+			f, err := value.ValueFloat()
+			if err != nil {
+				return fmt.Errorf("price: %w", err)
+			}
+			v.Price = float32(f)
+		}
+	}
+	if value, ok := node.Map["options"]; ok {
+		if value.Type == gojson.JSONNull {
+			v.Options = nil
+		} else {
+			if value.Type != gojson.JSONArray {
+				return errors.New("options: expected array")
+			}
+			items1 := make([][]int, len(value.Array))
+			for i1, item1 := range value.Array {
+				if item1.Type == gojson.JSONNull {
+					items1[i1] = nil
+				} else {
+					if item1.Type != gojson.JSONArray {
+						return fmt.Errorf("options[%d]: expected array", i1)
+					}
+					items2 := make([]int, len(item1.Array))
+					for i2, item2 := range item1.Array {
+						if item2.Type != gojson.JSONNull {
+							n, err := item2.ValueInt64()
+							if err != nil {
+								return fmt.Errorf("options[%d][%d]: %w", i1, i2, err)
+							}
+							if int64(int(n)) != n {
+								return fmt.Errorf("options[%d][%d]: %w", i1, i2, gojson.ErrOverflow)
+							}
+							items2[i2] = int(n)
+						}
+					}
+					items1[i1] = items2
+				}
+			}
+			v.Options = items1
+		}
+	}
+	return nil
+}
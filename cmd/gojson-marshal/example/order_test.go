@@ -0,0 +1,93 @@
+package example
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/FanFani4/gojson"
+)
+
+var data = []byte(`{"id":9007199254740993,"status":"paid","customer":{"email":"ann@example.com","name":"Ann \"A\" Smith\u2028"},` +
+	`"items":[{"sku":"A-1","qty":2,"price":9.99,"options":[[1,2],null,[]]},{"sku":"B-2","qty":1,"price":1e-7},{"sku":"C-3","qty":65535,"price":120}],` +
+	`"total":140.98,"paid":true,"created":"2024-05-01T10:00:00.123456789+02:00","coupon":"SPRING","tags":["new","gift"],` +
+	`"meta":{"channel":"web","ref":"ĂŞ"}}`)
+
+func TestOrder(t *testing.T) {
+	var order Order
+	if err := order.UnmarshalGoJSON(gojson.Unmarshal(data)); err != nil {
+		t.Fatal(err)
+	}
+	var expected Order
+	if err := json.Unmarshal(data, &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, order)
+	}
+
+	var buf bytes.Buffer
+	order.MarshalGoJSON(&buf)
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatalf("expected %s, got %s", data, buf.Bytes())
+	}
+	buf.Reset()
+	(&Order{Customer: &Customer{}}).MarshalGoJSON(&buf)
+	if got := buf.String(); got != `{"id":0,"status":"","customer":{"name":""},"items":null,"total":0,"paid":false,"created":"0001-01-01T00:00:00Z"}` {
+		t.Fatal("unexpected empty order", got)
+	}
+
+	errs := map[string]string{
+		`[]`:                                     `Order: expected object`,
+		`{"items": [{}, {"qty": 65536}]}`:        `items[1]: qty: number overflows requested type`,
+		`{"items": [{"options": [[1], [1.5]]}]}`: `items[0]: options[1][0]: number is not an integer`,
+		`{"meta": {"a": true}}`:                  `meta["a"]: expected string`,
+		`{"tags": {}}`:                           `tags: expected array`,
+		`{"created": "yesterday"}`:               `created: parsing time "yesterday" as "2006-01-02T15:04:05.999999999Z07:00": cannot parse "yesterday" as "2006"`,
+	}
+	for input, message := range errs {
+		if err := new(Order).UnmarshalGoJSON(gojson.Unmarshal([]byte(input))); err == nil || err.Error() != message {
+			t.Fatalf("%s: expected error %q, got %v", input, message, err)
+		}
+	}
+
+	order.Coupon, order.Tags = new(string), []string{"x"}
+	if err := order.UnmarshalGoJSON(gojson.Unmarshal([]byte(`{"coupon": null, "tags": null, "total": null}`))); err != nil ||
+		order.Coupon != nil || order.Tags != nil || order.Total != 140.98 {
+		t.Fatal("null is expected to clear only pointers, slices and maps", err)
+	}
+}
+
+var order = func() (order Order) {
+	json.Unmarshal(data, &order)
+	return
+}()
+
+func BenchmarkOrder_MarshalGoJSON(b *testing.B) {
+	var buf bytes.Buffer
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		order.MarshalGoJSON(&buf)
+	}
+}
+
+func BenchmarkOrder_EncodingJSONMarshal(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		json.Marshal(&order)
+	}
+}
+
+func BenchmarkOrder_UnmarshalGoJSON(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var o Order
+		o.UnmarshalGoJSON(gojson.Unmarshal(data))
+	}
+}
+
+func BenchmarkOrder_EncodingJSONUnmarshal(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var o Order
+		json.Unmarshal(data, &o)
+	}
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/FanFani4/gojson"
+)
+
+// annotation in doc comment of struct type requests generated methods
+const annotation = "gojson:generate"
+
+// fieldType is Go type of field the generator can read and write
+type fieldType struct {
+	kind string     // string, bool, int, uint, float, time, struct, ptr, slice or map
+	expr string     // type expression
+	bits int        // size of float
+	elem *fieldType // element of ptr, slice and map
+}
+
+type field struct {
+	name, key string
+	typ       *fieldType
+	omitEmpty bool
+}
+
+type structType struct {
+	name   string
+	fields []field
+}
+
+// errorContext builds error returned by generated code, format has a single %w verb for the cause
+type errorContext struct {
+	format string
+	args   []string
+}
+
+// with returns context of nested value, suffix is added to the name of the value
+func (c errorContext) with(suffix, arg string) errorContext {
+	return errorContext{
+		format: strings.Replace(c.format, ": %w", suffix+": %w", 1),
+		args:   append(c.args[:len(c.args):len(c.args)], arg),
+	}
+}
+
+// wrap returns expression of error wrapping cause
+func (c errorContext) wrap(g *generator, cause string) string {
+	g.imports["fmt"] = true
+	return fmt.Sprintf("fmt.Errorf(%s)", strings.Join(append([]string{strconv.Quote(c.format)}, append(c.args, cause)...), ", "))
+}
+
+// fail returns expression of error with message instead of cause
+func (c errorContext) fail(g *generator, message string) string {
+	format := strings.Replace(c.format, "%w", message, 1)
+	if len(c.args) == 0 {
+		g.imports["errors"] = true
+		return fmt.Sprintf("errors.New(%s)", strconv.Quote(strings.Replace(format, "%%", "%", -1)))
+	}
+	g.imports["fmt"] = true
+	return fmt.Sprintf("fmt.Errorf(%s)", strings.Join(append([]string{strconv.Quote(format)}, c.args...), ", "))
+}
+
+type generator struct {
+	locals  map[string]ast.Expr // types declared in the file
+	structs map[string]bool     // structs getting generated methods
+	imports map[string]bool
+	buf     bytes.Buffer
+	fn      bytes.Buffer // body of the function being generated
+	scratch bool         // function body uses scratch buffer for numbers
+}
+
+// generate returns formatted source with MarshalGoJSON and UnmarshalGoJSON methods of structs declared in src
+// that are listed in names or annotated with "//gojson:generate" comment if names are empty
+func generate(filename string, src []byte, names ...string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{locals: make(map[string]ast.Expr), structs: make(map[string]bool), imports: make(map[string]bool)}
+	var selected []*ast.TypeSpec
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+	for _, decl := range file.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.TypeSpec)
+			g.locals[spec.Name.Name] = spec.Type
+			if _, isStruct := spec.Type.(*ast.StructType); !isStruct || spec.TypeParams != nil {
+				continue
+			}
+			doc := spec.Doc
+			if doc == nil && len(decl.Specs) == 1 {
+				doc = decl.Doc
+			}
+			if wanted[spec.Name.Name] || len(wanted) == 0 && annotated(doc) {
+				selected = append(selected, spec)
+				g.structs[spec.Name.Name] = true
+			}
+		}
+	}
+	for _, name := range names {
+		if !g.structs[name] {
+			return nil, fmt.Errorf("struct %s is not declared in %s", name, filename)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%s has no structs annotated with //%s", filename, annotation)
+	}
+
+	var structs []structType
+	for _, spec := range selected {
+		s := structType{name: spec.Name.Name}
+		for _, f := range spec.Type.(*ast.StructType).Fields.List {
+			fields, err := g.fields(f)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %v", fset.Position(f.Pos()), s.name, err)
+			}
+			s.fields = append(s.fields, fields...)
+		}
+		structs = append(structs, s)
+	}
+
+	g.imports["bytes"] = true
+	var body bytes.Buffer
+	for _, s := range structs {
+		g.writeMarshal(s)
+		body.Write(g.buf.Bytes())
+		g.buf.Reset()
+		g.writeUnmarshal(s)
+		body.Write(g.buf.Bytes())
+		g.buf.Reset()
+	}
+
+	fmt.Fprintf(&g.buf, "// Code generated by gojson-marshal. DO NOT EDIT.\n\npackage %s\n\nimport (\n", file.Name.Name)
+	var imports []string
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		fmt.Fprintf(&g.buf, "\t%q\n", path)
+	}
+	g.buf.WriteString("\n\t\"github.com/FanFani4/gojson\"\n)\n")
+	g.buf.Write(body.Bytes())
+	return format.Source(g.buf.Bytes())
+}
+
+// annotated reports whether doc comment has a line with annotation
+func annotated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, comment := range doc.List {
+		if strings.TrimSpace(strings.TrimPrefix(comment.Text, "//")) == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+// fields returns fields declared by f honouring json tag
+func (g *generator) fields(f *ast.Field) ([]field, error) {
+	tag := ""
+	if f.Tag != nil {
+		unquoted, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return nil, err
+		}
+		tag = reflect.StructTag(unquoted).Get("json")
+	}
+	if tag == "-" {
+		return nil, nil
+	}
+	if len(f.Names) == 0 {
+		return nil, fmt.Errorf("embedded field %s is not supported", types.ExprString(f.Type))
+	}
+	options := strings.Split(tag, ",")
+	var fields []field
+	for _, name := range f.Names {
+		if !name.IsExported() {
+			continue
+		}
+		typ, err := g.resolve(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %v", name.Name, err)
+		}
+		result := field{name: name.Name, key: options[0], typ: typ}
+		if result.key == "" {
+			result.key = name.Name
+		}
+		for _, option := range options[1:] {
+			switch option {
+			case "omitempty":
+				if typ.kind == "struct" || typ.kind == "time" {
+					return nil, fmt.Errorf("field %s: omitempty option is not supported for struct %s, it is never empty", name.Name, typ.expr)
+				}
+				result.omitEmpty = true
+			case "string":
+				return nil, fmt.Errorf("field %s: string option is not supported", name.Name)
+			}
+		}
+		fields = append(fields, result)
+	}
+	return fields, nil
+}
+
+// basicKinds are kinds of predeclared types
+var basicKinds = map[string]*fieldType{
+	"string": {kind: "string"}, "bool": {kind: "bool"},
+	"int": {kind: "int"}, "int8": {kind: "int"}, "int16": {kind: "int"}, "int32": {kind: "int"}, "int64": {kind: "int"}, "rune": {kind: "int"},
+	"uint": {kind: "uint"}, "uint8": {kind: "uint"}, "uint16": {kind: "uint"}, "uint32": {kind: "uint"}, "uint64": {kind: "uint"}, "byte": {kind: "uint"},
+	"float32": {kind: "float", bits: 32}, "float64": {kind: "float", bits: 64},
+}
+
+// resolve returns type of field declared with expr
+func (g *generator) resolve(expr ast.Expr) (*fieldType, error) {
+	typ := &fieldType{expr: types.ExprString(expr)}
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if g.structs[expr.Name] {
+			typ.kind = "struct"
+			return typ, nil
+		}
+		basic, found := basicKinds[expr.Name]
+		for depth := 0; !found && depth < 10; depth++ {
+			local, ok := g.locals[expr.Name].(*ast.Ident)
+			if !ok {
+				return nil, fmt.Errorf("type %s isn't annotated struct or type based on predeclared type", typ.expr)
+			}
+			expr = local
+			basic, found = basicKinds[expr.Name]
+		}
+		if !found {
+			return nil, fmt.Errorf("type %s can't be resolved", typ.expr)
+		}
+		typ.kind, typ.bits = basic.kind, basic.bits
+	case *ast.SelectorExpr:
+		if typ.expr != "time.Time" {
+			return nil, fmt.Errorf("type %s is not supported", typ.expr)
+		}
+		typ.kind = "time"
+	case *ast.StarExpr:
+		typ.kind = "ptr"
+	case *ast.ArrayType:
+		if expr.Len != nil {
+			return nil, fmt.Errorf("array type %s is not supported, use slice", typ.expr)
+		}
+		typ.kind = "slice"
+	case *ast.MapType:
+		if key, ok := expr.Key.(*ast.Ident); !ok || key.Name != "string" {
+			return nil, fmt.Errorf("map type %s is not supported, keys must be string", typ.expr)
+		}
+		typ.kind = "map"
+	default:
+		return nil, fmt.Errorf("type %s is not supported", typ.expr)
+	}
+	var elem ast.Expr
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		elem = expr.X
+	case *ast.ArrayType:
+		elem = expr.Elt
+	case *ast.MapType:
+		elem = expr.Value
+	}
+	if elem != nil {
+		var err error
+		if typ.elem, err = g.resolve(elem); err != nil {
+			return nil, err
+		}
+	}
+	return typ, nil
+}
+
+func (g *generator) line(format string, args ...interface{}) {
+	fmt.Fprintf(&g.fn, format, args...)
+	g.fn.WriteByte('\n')
+}
+
+// region marshal
+
+// writeMarshal writes MarshalGoJSON method of s
+func (g *generator) writeMarshal(s structType) {
+	g.fn.Reset()
+	g.scratch = false
+	// written is "no" before the first member, "yes" after a member that is always written,
+	// "maybe" after omitted members, then comma variable tracks it
+	written := "no"
+	commaVar := false
+	for i, f := range s.fields {
+		var key bytes.Buffer
+		gojson.WriteString(&key, f.key)
+		key.WriteByte(':')
+		prefix := key.String()
+		e := "v." + f.name
+		if f.omitEmpty {
+			g.line("if %s {", nonEmpty(e, f.typ))
+		}
+		switch written {
+		case "yes":
+			prefix = "," + prefix
+		case "maybe":
+			commaVar = true
+			g.line("if comma {\nbuf.WriteByte(',')\n}")
+		}
+		g.line("buf.WriteString(%s)", quote(prefix))
+		g.encode(e, f.typ, 1, f.omitEmpty)
+		if f.omitEmpty {
+			if written != "yes" && i+1 < len(s.fields) {
+				g.line("comma = true")
+			}
+			g.line("}")
+			if written == "no" {
+				written = "maybe"
+			}
+		} else {
+			written = "yes"
+		}
+	}
+
+	fmt.Fprintf(&g.buf, "\n// MarshalGoJSON writes v as json object to buf\nfunc (v *%s) MarshalGoJSON(buf *bytes.Buffer) {\n", s.name)
+	g.buf.WriteString("if v == nil {\nbuf.WriteString(\"null\")\nreturn\n}\n")
+	if g.scratch {
+		g.buf.WriteString("var scratch [64]byte\n")
+	}
+	if commaVar {
+		g.buf.WriteString("comma := false\n")
+	}
+	g.buf.WriteString("buf.WriteByte('{')\n")
+	g.buf.Write(g.fn.Bytes())
+	g.buf.WriteString("buf.WriteByte('}')\n}\n")
+}
+
+// nonEmpty returns condition of value e not omitted by omitempty option
+func nonEmpty(e string, t *fieldType) string {
+	switch t.kind {
+	case "string":
+		return e + ` != ""`
+	case "bool":
+		return e
+	case "int", "uint", "float":
+		return e + " != 0"
+	case "ptr":
+		return e + " != nil"
+	}
+	return "len(" + e + ") != 0"
+}
+
+// convert returns e converted to predeclared type target unless it already has it
+func convert(target, e string, t *fieldType) string {
+	if t.expr == target {
+		return e
+	}
+	return target + "(" + e + ")"
+}
+
+// quote returns Go string literal of s, raw if it is possible
+func quote(s string) string {
+	if strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// encode writes code writing value e of type t, depth makes names of loop variables unique,
+// nonNil is set when e is known to be a non-nil pointer, slice or map
+func (g *generator) encode(e string, t *fieldType, depth int, nonNil bool) {
+	if !nonNil {
+		switch t.kind {
+		case "ptr", "slice", "map":
+			if t.kind == "ptr" && t.elem.kind == "struct" {
+				break
+			}
+			g.line("if %s == nil {\nbuf.WriteString(\"null\")\n} else {", e)
+			g.encode(e, t, depth, true)
+			g.line("}")
+			return
+		}
+	}
+	switch t.kind {
+	case "string":
+		g.line("gojson.WriteString(buf, %s)", convert("string", e, t))
+	case "bool":
+		g.imports["strconv"] = true
+		g.line("buf.WriteString(strconv.FormatBool(%s))", convert("bool", e, t))
+	case "int":
+		g.imports["strconv"], g.scratch = true, true
+		g.line("buf.Write(strconv.AppendInt(scratch[:0], %s, 10))", convert("int64", e, t))
+	case "uint":
+		g.imports["strconv"], g.scratch = true, true
+		g.line("buf.Write(strconv.AppendUint(scratch[:0], %s, 10))", convert("uint64", e, t))
+	case "float":
+		g.scratch = true
+		g.line("buf.Write(gojson.AppendFloat(scratch[:0], %s, %d))", convert("float64", e, t), t.bits)
+	case "time":
+		g.imports["time"] = true
+		g.line("gojson.WriteString(buf, %s.Format(time.RFC3339Nano))", e)
+	case "struct":
+		g.line("%s.MarshalGoJSON(buf)", e)
+	case "ptr":
+		if t.elem.kind == "struct" {
+			g.line("%s.MarshalGoJSON(buf)", e)
+			return
+		}
+		g.encode(deref(e, t.elem), t.elem, depth, false)
+	case "slice":
+		i := fmt.Sprintf("i%d", depth)
+		g.line("buf.WriteByte('[')\nfor %s := range %s {", i, e)
+		g.line("if %s > 0 {\nbuf.WriteByte(',')\n}", i)
+		g.encode(fmt.Sprintf("%s[%s]", e, i), t.elem, depth+1, false)
+		g.line("}\nbuf.WriteByte(']')")
+	case "map":
+		g.imports["sort"] = true
+		i, keys, elem := fmt.Sprintf("i%d", depth), fmt.Sprintf("keys%d", depth), fmt.Sprintf("elem%d", depth)
+		g.line("%s := make([]string, 0, len(%s))\nfor key := range %s {\n%s = append(%s, key)\n}\nsort.Strings(%s)", keys, e, e, keys, keys, keys)
+		g.line("buf.WriteByte('{')\nfor %s, key := range %s {", i, keys)
+		g.line("if %s > 0 {\nbuf.WriteByte(',')\n}\ngojson.WriteString(buf, key)\nbuf.WriteByte(':')\n%s := %s[key]", i, elem, e)
+		g.encode(elem, t.elem, depth+1, false)
+		g.line("}\nbuf.WriteByte('}')")
+	}
+}
+
+// deref returns expression of value pointed by e, parenthesized if it is going to be indexed
+func deref(e string, elem *fieldType) string {
+	if elem.kind == "slice" || elem.kind == "map" {
+		return "(*" + e + ")"
+	}
+	return "*" + e
+}
+
+// endregion
+
+// region unmarshal
+
+// writeUnmarshal writes UnmarshalGoJSON method of s
+func (g *generator) writeUnmarshal(s structType) {
+	g.fn.Reset()
+	for _, f := range s.fields {
+		g.line("if value, ok := node.Map[%s]; ok {", strconv.Quote(f.key))
+		g.decode("v."+f.name, "value", f.typ, errorContext{format: strings.Replace(f.key, "%", "%%", -1) + ": %w"}, 1, false)
+		g.line("}")
+	}
+	g.imports["errors"] = true
+	fmt.Fprintf(&g.buf, "\n// UnmarshalGoJSON sets fields of v from json object node, members without field are ignored\n")
+	fmt.Fprintf(&g.buf, "func (v *%s) UnmarshalGoJSON(node *gojson.GoJSON) error {\n", s.name)
+	fmt.Fprintf(&g.buf, "if node.Type != gojson.JSONObject {\nreturn errors.New(%s)\n}\n", strconv.Quote(s.name+": expected object"))
+	g.buf.Write(g.fn.Bytes())
+	g.buf.WriteString("return nil\n}\n")
+}
+
+// decode writes code setting lhs from node named value, null leaves values untouched
+// and sets pointers, slices and maps to nil like Decode does it, notNull is set when value is known not to be null
+func (g *generator) decode(lhs, value string, t *fieldType, ctx errorContext, depth int, notNull bool) {
+	if !notNull {
+		switch t.kind {
+		case "ptr", "slice", "map":
+			g.line("if %s.Type == gojson.JSONNull {\n%s = nil\n} else {", value, lhs)
+		default:
+			g.line("if %s.Type != gojson.JSONNull {", value)
+		}
+		g.decode(lhs, value, t, ctx, depth, true)
+		g.line("}")
+		return
+	}
+
+	switch t.kind {
+	case "string":
+		// ValueString returns text of any scalar
+		g.line("if %s.Type != gojson.JSONString {\nreturn %s\n}", value, ctx.fail(g, "expected string"))
+		g.line("s, _ := %s.ValueString()\n%s = %s", value, lhs, convert(t.expr, "s", &fieldType{expr: "string"}))
+	case "bool":
+		g.line("b, err := %s.ValueBool()\nif err != nil {\nreturn %s\n}", value, ctx.wrap(g, "err"))
+		g.line("%s = %s", lhs, convert(t.expr, "b", &fieldType{expr: "bool"}))
+	case "int", "uint":
+		getter, wide := "ValueInt64", "int64"
+		if t.kind == "uint" {
+			getter, wide = "ValueUint64", "uint64"
+		}
+		g.line("n, err := %s.%s()\nif err != nil {\nreturn %s\n}", value, getter, ctx.wrap(g, "err"))
+		if t.expr != wide {
+			g.line("if %s(%s(n)) != n {\nreturn %s\n}", wide, t.expr, ctx.wrap(g, "gojson.ErrOverflow"))
+		}
+		g.line("%s = %s", lhs, convert(t.expr, "n", &fieldType{expr: wide}))
+	case "float":
+		g.line("f, err := %s.ValueFloat()\nif err != nil {\nreturn %s\n}", value, ctx.wrap(g, "err"))
+		g.line("%s = %s", lhs, convert(t.expr, "f", &fieldType{expr: "float64"}))
+	case "time":
+		g.imports["time"] = true
+		g.line("if %s.Type != gojson.JSONString {\nreturn %s\n}", value, ctx.fail(g, "expected string"))
+		g.line("s, _ := %s.ValueString()\nt, err := time.Parse(time.RFC3339Nano, s)\nif err != nil {\nreturn %s\n}\n%s = t", value, ctx.wrap(g, "err"), lhs)
+	case "struct":
+		g.line("if err := %s.UnmarshalGoJSON(%s); err != nil {\nreturn %s\n}", lhs, value, ctx.wrap(g, "err"))
+	case "ptr":
+		g.line("if %s == nil {\n%s = new(%s)\n}", lhs, lhs, t.elem.expr)
+		if t.elem.kind == "struct" {
+			g.line("if err := %s.UnmarshalGoJSON(%s); err != nil {\nreturn %s\n}", lhs, value, ctx.wrap(g, "err"))
+			return
+		}
+		g.decode(deref(lhs, t.elem), value, t.elem, ctx, depth, true)
+	case "slice":
+		i, items, item := fmt.Sprintf("i%d", depth), fmt.Sprintf("items%d", depth), fmt.Sprintf("item%d", depth)
+		g.line("if %s.Type != gojson.JSONArray {\nreturn %s\n}", value, ctx.fail(g, "expected array"))
+		g.line("%s := make(%s, len(%s.Array))\nfor %s, %s := range %s.Array {", items, t.expr, value, i, item, value)
+		g.decode(fmt.Sprintf("%s[%s]", items, i), item, t.elem, ctx.with("[%d]", i), depth+1, false)
+		g.line("}\n%s = %s", lhs, items)
+	case "map":
+		key, m, item, elem := fmt.Sprintf("key%d", depth), fmt.Sprintf("m%d", depth), fmt.Sprintf("item%d", depth), fmt.Sprintf("elem%d", depth)
+		g.line("if %s.Type != gojson.JSONObject {\nreturn %s\n}", value, ctx.fail(g, "expected object"))
+		g.line("%s := make(%s, len(%s.Map))\nfor %s, %s := range %s.Map {\nvar %s %s", m, t.expr, value, key, item, value, elem, t.elem.expr)
+		g.decode(elem, item, t.elem, ctx.with("[%q]", key), depth+1, false)
+		g.line("%s[%s] = %s\n}\n%s = %s", m, key, elem, lhs, m)
+	}
+}
+
+// endregion
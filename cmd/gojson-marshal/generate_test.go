@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := os.ReadFile("example/order.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := generate("order.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := os.ReadFile("example/order_gojson.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(generated, expected) {
+		t.Fatal("example/order_gojson.go is outdated, run go generate ./cmd/gojson-marshal/example")
+	}
+
+	generated, err = generate("a.go", []byte("package a\n\ntype A struct {\n\tOnly string `json:\"o%,omitempty\"`\n\tnext int\n}\n\ntype B struct{ X int }\n"), "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"func (v *A) MarshalGoJSON(buf *bytes.Buffer) {", "\tif v.Only != \"\" {\n\t\tbuf.WriteString(`\"o%\":`)", `errors.New("o%: expected string")`} {
+		if !strings.Contains(string(generated), s) {
+			t.Fatalf("expected %q in\n%s", s, generated)
+		}
+	}
+	if strings.Contains(string(generated), "comma") || strings.Contains(string(generated), "\"fmt\"") || strings.Contains(string(generated), "*B)") {
+		t.Fatalf("unexpected source\n%s", generated)
+	}
+
+	errs := map[string]string{
+		"package a\n\ntype A struct{ X int }":                                                                   "a.go has no structs annotated with //gojson:generate",
+		"package a\n\n//gojson:generate\ntype A struct{ X chan int }":                                           "a.go:4:16: A: field X: type chan int is not supported",
+		"package a\n\n//gojson:generate\ntype A struct{ B }\ntype B int":                                        "a.go:4:16: A: embedded field B is not supported",
+		"package a\n\nimport \"time\"\n\n//gojson:generate\ntype A struct{ T time.Time `json:\",omitempty\"` }": "a.go:6:16: A: field T: omitempty option is not supported for struct time.Time, it is never empty",
+		"package a\n\n//gojson:generate\ntype A struct{ M map[int]A }":                                          "a.go:4:16: A: field M: map type map[int]A is not supported, keys must be string",
+		"package a\n\n//gojson:generate\ntype A struct{ B B }\ntype B struct{}":                                 "a.go:4:16: A: field B: type B isn't annotated struct or type based on predeclared type",
+	}
+	for src, message := range errs {
+		if _, err := generate("a.go", []byte(src)); err == nil || err.Error() != message {
+			t.Fatalf("%q: expected error %q, got %v", src, message, err)
+		}
+	}
+	if _, err := generate("a.go", []byte("package a\n\ntype A struct{}"), "C"); err == nil || err.Error() != "struct C is not declared in a.go" {
+		t.Fatal("unexpected error", err)
+	}
+}
@@ -0,0 +1,57 @@
+// Command gojson-marshal generates MarshalGoJSON and UnmarshalGoJSON methods that write and read
+// structs without reflection, it is meant to be run by go generate:
+//
+//	//go:generate go run github.com/FanFani4/gojson/cmd/gojson-marshal
+//
+//	//gojson:generate
+//	type Order struct {
+//		ID    int64    `json:"id"`
+//		Items []Item   `json:"items,omitempty"`
+//	}
+//
+// methods are generated for structs of $GOFILE annotated with "//gojson:generate" comment or listed in -type flag
+// and written to file_gojson.go next to it, json tag names, omitempty and "-" are honoured,
+// omitempty is rejected on struct and time.Time fields as they are never empty,
+// fields can be of predeclared scalar types or types based on them, time.Time, annotated structs,
+// pointers, slices and maps with string keys of them
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated struct names, annotated structs by default")
+	output := flag.String("o", "", "output file, file_gojson.go by default")
+	flag.Parse()
+
+	input := os.Getenv("GOFILE")
+	if flag.NArg() > 0 {
+		input = flag.Arg(0)
+	}
+	if input == "" {
+		fmt.Fprintln(os.Stderr, "gojson-marshal: no input file, run it with go generate or pass the file name")
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = strings.TrimSuffix(input, ".go") + "_gojson.go"
+	}
+	var names []string
+	if *typeNames != "" {
+		names = strings.Split(*typeNames, ",")
+	}
+
+	src, err := os.ReadFile(input)
+	if err == nil {
+		if src, err = generate(input, src, names...); err == nil {
+			err = os.WriteFile(*output, src, 0644)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gojson-marshal: %v\n", err)
+		os.Exit(1)
+	}
+}
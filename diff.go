@@ -0,0 +1,266 @@
+package gojson
+
+import (
+	"strconv"
+)
+
+// DiffOptions configures Diff
+type DiffOptions struct {
+	// DetectMoves compares arrays by longest common subsequence and turns elements
+	// that were removed and added elsewhere in the same array into "move" operations
+	DetectMoves bool
+	// IdentityKeys lists object members identifying array elements, e.g. "id",
+	// elements with equal identity are matched and diffed recursively instead of replaced,
+	// the first listed member an element has is used
+	IdentityKeys []string
+}
+
+// Diff returns RFC 6902 JSON Patch that transforms a into b, applying it with ApplyPatch to a copy of a gives b
+// numbers are compared by value, arrays are compared index by index unless DetectMoves or IdentityKeys are set
+// and the arrays differ in few enough elements to match each with each
+func Diff(a, b *GoJSON, opts ...DiffOptions) *GoJSON {
+	d := differ{patch: NewArray()}
+	if len(opts) > 0 {
+		d.DiffOptions = opts[0]
+	}
+	d.diff(nil, a, b)
+	return d.patch
+}
+
+// differ collects patch operations
+type differ struct {
+	DiffOptions
+	patch *GoJSON
+}
+
+// operation appends operation to the patch, from and value are optional
+func (d *differ) operation(op string, path []string, from []string, value *GoJSON) {
+	node := NewObject()
+	node.Set("op", stringNode(op))
+	if from != nil {
+		node.Set("from", stringNode(FormatPointer(from...)))
+	}
+	node.Set("path", stringNode(FormatPointer(path...)))
+	if value != nil {
+		node.Set("value", value.Clone())
+	}
+	d.patch.Set(-1, node)
+}
+
+func (d *differ) diff(path []string, a, b *GoJSON) {
+	if nodesEqual(a, b) {
+		return
+	}
+	switch {
+	case a.Type == JSONObject && b.Type == JSONObject:
+		for _, key := range a.orderedKeys() {
+			if _, found := b.Map[key]; !found {
+				d.operation("remove", appendToken(path, key), nil, nil)
+			}
+		}
+		for _, key := range b.orderedKeys() {
+			if value, found := a.Map[key]; found {
+				d.diff(appendToken(path, key), value, b.Map[key])
+			} else {
+				d.operation("add", appendToken(path, key), nil, b.Map[key])
+			}
+		}
+	case a.Type == JSONArray && b.Type == JSONArray:
+		if d.DetectMoves || len(d.IdentityKeys) > 0 {
+			d.diffMatched(path, a.Array, b.Array)
+		} else {
+			d.diffIndexed(path, a.Array, b.Array)
+		}
+	default:
+		d.operation("replace", path, nil, b)
+	}
+}
+
+// diffIndexed compares arrays element by element and removes or adds the tail
+func (d *differ) diffIndexed(path []string, a, b []*GoJSON) {
+	common := len(a)
+	if len(b) < common {
+		common = len(b)
+	}
+	for i := 0; i < common; i++ {
+		d.diff(appendToken(path, strconv.Itoa(i)), a[i], b[i])
+	}
+	for i := len(a) - 1; i >= common; i-- {
+		d.operation("remove", appendToken(path, strconv.Itoa(i)), nil, nil)
+	}
+	for i := common; i < len(b); i++ {
+		d.operation("add", appendToken(path, strconv.Itoa(i)), nil, b[i])
+	}
+}
+
+// diffMatched keeps the longest common subsequence of matching elements in place,
+// removes and adds the rest, or moves them if DetectMoves is set
+func (d *differ) diffMatched(path []string, a, b []*GoJSON) {
+	// source[j] is index of element of a that becomes b[j] or -1 if b[j] is added
+	source := make([]int, len(b))
+	for j := range source {
+		source[j] = -1
+	}
+	pairs, ok := d.commonSubsequence(a, b)
+	if !ok {
+		d.diffIndexed(path, a, b)
+		return
+	}
+	used := make([]bool, len(a))
+	for _, pair := range pairs {
+		source[pair[1]] = pair[0]
+		used[pair[0]] = true
+	}
+	stable := make([]bool, len(b))
+	for j, i := range source {
+		stable[j] = i >= 0
+	}
+	if d.DetectMoves {
+		for j := range b {
+			if source[j] >= 0 {
+				continue
+			}
+			for i := range a {
+				if !used[i] && d.match(a[i], b[j]) {
+					source[j] = i
+					used[i] = true
+					break
+				}
+			}
+		}
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		if !used[i] {
+			d.operation("remove", appendToken(path, strconv.Itoa(i)), nil, nil)
+		}
+	}
+	// current holds indexes into a of elements in the array as operations are applied
+	current := make([]int, 0, len(a))
+	for i := range a {
+		if used[i] {
+			current = append(current, i)
+		}
+	}
+	position := func(i int) int {
+		for p, value := range current {
+			if value == i {
+				return p
+			}
+		}
+		return -1
+	}
+	// moved elements are put right after the preceding element of b that is already in place,
+	// once all are moved the elements are in the order of b
+	for j, i := range source {
+		if i < 0 || stable[j] {
+			continue
+		}
+		from, to := position(i), 0
+		for k := j - 1; k >= 0; k-- {
+			if source[k] >= 0 {
+				if to = position(source[k]); from > to {
+					to++
+				}
+				break
+			}
+		}
+		if from != to {
+			d.operation("move", appendToken(path, strconv.Itoa(to)), appendToken(path, strconv.Itoa(from)), nil)
+			current = append(current[:from], current[from+1:]...)
+			current = append(current[:to], append([]int{i}, current[to:]...)...)
+		}
+	}
+	for j, i := range source {
+		if i < 0 {
+			d.operation("add", appendToken(path, strconv.Itoa(j)), nil, b[j])
+		}
+	}
+	for j, i := range source {
+		if i >= 0 {
+			d.diff(appendToken(path, strconv.Itoa(j)), a[i], b[j])
+		}
+	}
+}
+
+// maxSubsequenceCells limits the table of the longest common subsequence of the differing middles of arrays,
+// larger arrays are compared index by index
+const maxSubsequenceCells = 1 << 20
+
+// commonSubsequence returns index pairs of the longest common subsequence of matching elements,
+// it is false if the arrays differ in too many elements to compare each with each
+func (d *differ) commonSubsequence(a, b []*GoJSON) ([][2]int, bool) {
+	var pairs [][2]int
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && d.match(a[prefix], b[prefix]) {
+		pairs = append(pairs, [2]int{prefix, prefix})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && d.match(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(a)*len(b) > maxSubsequenceCells {
+		return nil, false
+	}
+	// lengths[i][j] is length of the longest common subsequence of a[i:] and b[j:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if d.match(a[i], b[j]) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case d.match(a[i], b[j]):
+			pairs = append(pairs, [2]int{prefix + i, prefix + j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	for k := suffix; k > 0; k-- {
+		pairs = append(pairs, [2]int{prefix + len(a) + suffix - k, prefix + len(b) + suffix - k})
+	}
+	return pairs, true
+}
+
+// match reports whether array elements have equal identity, elements without identity match if they are equal
+func (d *differ) match(a, b *GoJSON) bool {
+	keyA, idA := d.identity(a)
+	keyB, idB := d.identity(b)
+	if idA != nil || idB != nil {
+		return keyA == keyB && idA != nil && idB != nil && nodesEqual(idA, idB)
+	}
+	return nodesEqual(a, b)
+}
+
+// identity returns the first identity key the object has and its value
+func (d *differ) identity(node *GoJSON) (string, *GoJSON) {
+	if node.Type == JSONObject {
+		for _, key := range d.IdentityKeys {
+			if value, found := node.Map[key]; found {
+				return key, value
+			}
+		}
+	}
+	return "", nil
+}
+
+// appendToken returns a new slice of path tokens, path itself is shared between siblings
+func appendToken(path []string, token string) []string {
+	return append(path[:len(path):len(path)], token)
+}
@@ -0,0 +1,105 @@
+package gojson
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		opts     DiffOptions
+		expected string
+	}{
+		{`{"a": 1, "b": [1, 2]}`, `{"a": 1.0, "b": [1, 2]}`, DiffOptions{}, `[]`},
+		{`{"a": 1, "b": {"c": 2}}`, `{"b": {"c": 3}, "d": null}`, DiffOptions{},
+			`[{"op":"remove","path":"/a"},{"op":"replace","path":"/b/c","value":3},{"op":"add","path":"/d","value":null}]`},
+		{`{"a/b": [1, 2, 3]}`, `{"a/b": [1, 5]}`, DiffOptions{},
+			`[{"op":"replace","path":"/a~1b/1","value":5},{"op":"remove","path":"/a~1b/2"}]`},
+		{`[1]`, `[1, {"x": 1}]`, DiffOptions{}, `[{"op":"add","path":"/1","value":{"x":1}}]`},
+		{`[1, 2]`, `"x"`, DiffOptions{}, `[{"op":"replace","path":"","value":"x"}]`},
+		{`[1, 2, 3, 4]`, `[0, 1, 3, 4]`, DiffOptions{DetectMoves: true},
+			`[{"op":"remove","path":"/1"},{"op":"add","path":"/0","value":0}]`},
+		{`[1, 2, 3, 4]`, `[2, 3, 4, 1]`, DiffOptions{DetectMoves: true},
+			`[{"op":"move","from":"/0","path":"/3"}]`},
+		{`["a", "b", "c", "d"]`, `["d", "b", "c", "a"]`, DiffOptions{DetectMoves: true},
+			`[{"op":"move","from":"/3","path":"/0"},{"op":"move","from":"/1","path":"/3"}]`},
+		{`[{"id": 1, "v": "a"}, {"id": 2, "v": "b"}]`, `[{"id": 2, "v": "c"}, {"id": 1, "v": "a"}]`,
+			DiffOptions{IdentityKeys: []string{"id"}},
+			`[{"op":"remove","path":"/0"},{"op":"add","path":"/1","value":{"id":1,"v":"a"}},{"op":"replace","path":"/0/v","value":"c"}]`},
+		{`[{"id": 1, "v": "a"}, {"id": 2, "v": "b"}]`, `[{"id": 2, "v": "c"}, {"id": 1, "v": "a"}]`,
+			DiffOptions{IdentityKeys: []string{"id"}, DetectMoves: true},
+			`[{"op":"move","from":"/0","path":"/1"},{"op":"replace","path":"/0/v","value":"c"}]`},
+		{`[{"id": 1}, {"key": 1}]`, `[{"key": 1, "x": 2}]`, DiffOptions{IdentityKeys: []string{"id", "key"}},
+			`[{"op":"remove","path":"/0"},{"op":"add","path":"/0/x","value":2}]`},
+	}
+	for _, c := range cases {
+		a, b := Unmarshal([]byte(c.a)), Unmarshal([]byte(c.b))
+		patch := Diff(a, b, c.opts)
+		if out := string(patch.Marshal()); out != c.expected {
+			t.Fatalf("%s -> %s: expected %s, got %s", c.a, c.b, c.expected, out)
+		}
+		if err := ApplyPatch(a, patch); err != nil || !nodesEqual(a, b) {
+			t.Fatalf("%s -> %s: patch gives %s, %v", c.a, c.b, a.Marshal(), err)
+		}
+	}
+}
+
+func TestDiff_Apply(t *testing.T) {
+	docs := []string{
+		`[]`, `[1, 2, 3]`, `[3, 2, 1]`, `[1, [2, 3], {"a": 1}]`, `[{"a": 1}, [2, 3], 1, 1]`,
+		`[{"id": 1, "n": [1]}, {"id": 2}, {"id": 3}, 4]`, `[4, {"id": 3, "n": 1}, {"id": 1, "n": [2, 1]}, {"id": 5}]`,
+		`{"a": [1, 2, 3, 4, 5], "b": {"c": [{"id": 1}]}}`, `{"a": [5, 1, 3, 2, 6], "b": {"c": [{"id": 2}, {"id": 1, "x": 0}]}}`,
+		`null`, `"text"`,
+	}
+	options := []DiffOptions{{}, {DetectMoves: true}, {IdentityKeys: []string{"id"}}, {DetectMoves: true, IdentityKeys: []string{"id"}}}
+	for _, opts := range options {
+		for _, x := range docs {
+			for _, y := range docs {
+				a, b := Unmarshal([]byte(x)), Unmarshal([]byte(y))
+				patch := Diff(a, b, opts)
+				if err := ApplyPatch(a, patch); err != nil || !nodesEqual(a, b) {
+					t.Fatalf("%s -> %s with %+v: patch %s gives %s, %v", x, y, opts, patch.Marshal(), a.Marshal(), err)
+				}
+			}
+		}
+	}
+}
+
+func TestDiff_LargeArray(t *testing.T) {
+	var ascending, descending, changed strings.Builder
+	for _, b := range []*strings.Builder{&ascending, &descending, &changed} {
+		b.WriteByte('[')
+	}
+	const n = 10000
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			ascending.WriteByte(',')
+			descending.WriteByte(',')
+			changed.WriteByte(',')
+		}
+		ascending.WriteString(strconv.Itoa(i))
+		descending.WriteString(strconv.Itoa(n - 1 - i))
+		if i == n/2 {
+			changed.WriteString(`"x"`)
+		} else {
+			changed.WriteString(strconv.Itoa(i))
+		}
+	}
+	for _, b := range []*strings.Builder{&ascending, &descending, &changed} {
+		b.WriteByte(']')
+	}
+	opts := DiffOptions{DetectMoves: true}
+	a, b := Unmarshal([]byte(ascending.String())), Unmarshal([]byte(changed.String()))
+	patch := Diff(a, b, opts)
+	if string(patch.Marshal()) != `[{"op":"remove","path":"/5000"},{"op":"add","path":"/5000","value":"x"}]` {
+		t.Fatalf("expected only the middle element changed, got %s", patch.Marshal())
+	}
+	// arrays differing everywhere are compared index by index
+	b = Unmarshal([]byte(descending.String()))
+	patch = Diff(a, b, opts)
+	if err := ApplyPatch(a, patch); err != nil || !nodesEqual(a, b) || len(patch.Array) != n {
+		t.Fatalf("expected %d replace operations, got %d, %v", n, len(patch.Array), err)
+	}
+}
@@ -0,0 +1,153 @@
+package gojson
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"sort"
+)
+
+// EqualOptions configures Equal
+type EqualOptions struct {
+	NumbersByValue   bool // 1, 1.0 and 1e0 are equal, by default numbers are compared by their text
+	IgnoreArrayOrder bool // arrays are equal if they have the same elements in any order
+}
+
+// Equal reports whether a and b are deeply equal, object keys order is ignored
+func Equal(a, b *GoJSON, opts ...EqualOptions) bool {
+	var o EqualOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	return o.equal(a, b)
+}
+
+// byValue compares numbers by value, it is used by diff, merge, patch and JSONPath comparisons
+var byValue = EqualOptions{NumbersByValue: true}
+
+// nodesEqual compares json values deeply, numbers are compared by value
+func nodesEqual(a, b *GoJSON) bool {
+	return byValue.equal(a, b)
+}
+
+func (o *EqualOptions) equal(a, b *GoJSON) bool {
+	if isNumber(a) && isNumber(b) {
+		// integers and floats with the same text are the same number
+		if o.NumbersByValue {
+			return compareNumbers(a.Bytes, b.Bytes) == 0
+		}
+		return string(a.Bytes) == string(b.Bytes)
+	}
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case JSONObject:
+		if len(a.Map) != len(b.Map) {
+			return false
+		}
+		for key, value := range a.Map {
+			other, found := b.Map[key]
+			if !found || !o.equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case JSONArray:
+		if len(a.Array) != len(b.Array) {
+			return false
+		}
+		if o.IgnoreArrayOrder {
+			return o.sameElements(a.Array, b.Array)
+		}
+		for i := range a.Array {
+			if !o.equal(a.Array[i], b.Array[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return string(a.Bytes) == string(b.Bytes)
+}
+
+// sameElements matches every element of a with a distinct equal element of b
+func (o *EqualOptions) sameElements(a, b []*GoJSON) bool {
+	used := make([]bool, len(b))
+	for _, value := range a {
+		found := false
+		for i, other := range b {
+			if !used[i] && o.equal(value, other) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash returns SHA-256 digest of the content, it doesn't depend on object keys order or number formatting,
+// values equal by Equal with NumbersByValue have the same hash
+func (g *GoJSON) Hash() [sha256.Size]byte {
+	h := sha256.New()
+	writeHash(h, g)
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// writeHash writes type tag and length prefixed content of the node
+func writeHash(h hash.Hash, g *GoJSON) {
+	var buf [9]byte
+	writeBytes := func(tag byte, b []byte) {
+		buf[0] = tag
+		binary.BigEndian.PutUint64(buf[1:], uint64(len(b)))
+		h.Write(buf[:])
+		h.Write(b)
+	}
+	switch g.Type {
+	case JSONInt, JSONFloat:
+		writeBytes('n', []byte(canonicalNumber(g.Bytes)))
+	case JSONString:
+		writeBytes('s', g.Bytes)
+	case JSONBool:
+		writeBytes('b', g.Bytes)
+	case JSONNull:
+		writeBytes('0', nil)
+	case JSONObject:
+		keys := make([]string, 0, len(g.Map))
+		for key := range g.Map {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		writeBytes('o', nil)
+		binary.BigEndian.PutUint64(buf[1:], uint64(len(keys)))
+		h.Write(buf[1:])
+		for _, key := range keys {
+			writeBytes('k', []byte(key))
+			writeHash(h, g.Map[key])
+		}
+	case JSONArray:
+		writeBytes('a', nil)
+		binary.BigEndian.PutUint64(buf[1:], uint64(len(g.Array)))
+		h.Write(buf[1:])
+		for _, value := range g.Array {
+			writeHash(h, value)
+		}
+	default:
+		writeBytes('?', nil)
+	}
+}
+
+// canonicalNumber returns the same text for numbers with equal value
+func canonicalNumber(text []byte) string {
+	if r, err := Number(text).Rat(); err == nil {
+		return r.RatString()
+	}
+	if f, err := Number(text).BigFloat(); err == nil {
+		return f.Text('g', -1)
+	}
+	return string(text)
+}
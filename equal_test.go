@@ -0,0 +1,63 @@
+package gojson
+
+import (
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		opts   EqualOptions
+		result bool
+	}{
+		{`{"a": 1, "b": [true, null, "x"]}`, `{"b": [true, null, "x"], "a": 1}`, EqualOptions{}, true},
+		{`{"a": 1}`, `{"a": 1.0}`, EqualOptions{}, false},
+		{`{"a": 1}`, `{"a": 1.0}`, EqualOptions{NumbersByValue: true}, true},
+		{`[100, -0, 0.5]`, `[1e2, 0, 5E-1]`, EqualOptions{NumbersByValue: true}, true},
+		{`[1, 2]`, `[1, 3]`, EqualOptions{NumbersByValue: true}, false},
+		{`[1, 2, 2]`, `[2, 1, 2]`, EqualOptions{}, false},
+		{`[1, 2, 2]`, `[2, 1, 2]`, EqualOptions{IgnoreArrayOrder: true}, true},
+		{`[1, 2, 2]`, `[2, 1, 1]`, EqualOptions{IgnoreArrayOrder: true}, false},
+		{`[[1, 2], {"a": [3, 4]}]`, `[{"a": [4, 3.0]}, [2, 1]]`, EqualOptions{IgnoreArrayOrder: true, NumbersByValue: true}, true},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, EqualOptions{}, false},
+		{`{"a": 1}`, `{"b": 1}`, EqualOptions{}, false},
+		{`"1"`, `1`, EqualOptions{NumbersByValue: true}, false},
+		{`null`, `false`, EqualOptions{}, false},
+	}
+	for _, c := range cases {
+		if Equal(Unmarshal([]byte(c.a)), Unmarshal([]byte(c.b)), c.opts) != c.result {
+			t.Fatalf("%s == %s with %+v: expected %v", c.a, c.b, c.opts, c.result)
+		}
+	}
+	// number type tag doesn't matter, only the text
+	float := &GoJSON{Type: JSONFloat, Bytes: []byte("1")}
+	if !Equal(float, Unmarshal([]byte(`1`))) || !nodesEqual(float, Unmarshal([]byte(`1.0`))) || Equal(float, Unmarshal([]byte(`1.0`))) {
+		t.Fatal("expected numbers compared by text or value regardless of type")
+	}
+}
+
+func TestGoJSON_Hash(t *testing.T) {
+	same := [][2]string{
+		{`{"a": 1, "b": {"c": [1, 2]}}`, `{"b": {"c": [1.0, 2e0]}, "a": 1}`},
+		{`[0.5, -0, 100000000000000000000]`, `[5e-1, 0, 1e20]`},
+		{`"é"`, `"é"`},
+		{`1e400`, `10e399`},
+	}
+	for _, c := range same {
+		if Unmarshal([]byte(c[0])).Hash() != Unmarshal([]byte(c[1])).Hash() {
+			t.Fatalf("%s and %s have different hashes", c[0], c[1])
+		}
+	}
+	different := []string{
+		`null`, `false`, `true`, `0`, `1`, `""`, `"0"`, `"null"`, `[]`, `{}`, `[[]]`, `[null]`, `[[], []]`, `[[[]]]`,
+		`{"a": "b"}`, `{"ab": ""}`, `{"a": {"b": null}}`, `{"a": null, "b": null}`, `["a", "b"]`, `["ab"]`, `[1, 2]`, `[2, 1]`,
+	}
+	seen := map[[32]byte]string{}
+	for _, doc := range different {
+		hash := Unmarshal([]byte(doc)).Hash()
+		if other, found := seen[hash]; found {
+			t.Fatalf("%s and %s have the same hash", doc, other)
+		}
+		seen[hash] = doc
+	}
+}
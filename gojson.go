@@ -1,5 +1,9 @@
 package gojson
 
+import (
+	"sort"
+)
+
 /*
 package GoJSON provides methods for Marshaling/Unmarshaling and editing JSON
 */
@@ -21,12 +25,16 @@ const (
 /*
 GoJSON is base json type
 expected types are JSONObject and JSONArray
+object keys keep the order they were parsed or Set in,
+keys added to Map directly follow them in sorted order
 */
 type GoJSON struct {
 	Type     JSONType
 	Bytes    []byte
 	Map      map[string]*GoJSON
 	Array    []*GoJSON
+	keys     []string // insertion order of Map keys
+	parent   *GoJSON  // object or array the node was last attached to
 }
 
 // ToMap transforms json to map[string]interface{}
@@ -99,12 +107,15 @@ func (g *GoJSON) Delete(key interface{}) string {
 	switch g.Type {
 	case JSONObject:
 		if strKey, ok := key.(string); ok {
+			g.detach(g.Map[strKey])
 			delete(g.Map, strKey)
+			g.removeKey(strKey)
 		} else {
 			return "You can delete from object just by string key"
 		}
 	case JSONArray:
 		if index, ok := key.(int); ok {
+			g.detach(g.Array[index])
 			g.Array = append(g.Array[:index], g.Array[index+1:]...)
 		} else {
 			return "You can delete from array just by index"
@@ -135,8 +146,8 @@ func (g *GoJSON) Update(json *GoJSON) string {
 		return ""
 	}
 
-	for key, value := range json.Map {
-		err := g.Set(key, value)
+	for _, key := range json.orderedKeys() {
+		err := g.Set(key, json.Map[key])
 		if err != "" {
 			return err
 		}
@@ -149,14 +160,7 @@ func (g *GoJSON) Keys() []string {
 	if g.Type != JSONObject || g.Map == nil || len(g.Map) == 0 {
 		return []string{}
 	}
-	response := make([]string, len(g.Map))
-
-	i := 0
-	for key := range g.Map {
-		response[i] = key
-		i++
-	}
-	return response
+	return append([]string(nil), g.orderedKeys()...)
 }
 
 // Values returns values of object or array
@@ -166,10 +170,8 @@ func (g *GoJSON) Values() (response []*GoJSON) {
 			return
 		}
 		response = make([]*GoJSON, len(g.Map))
-		i := 0
-		for _, value := range g.Map {
-			response[i] = value
-			i++
+		for i, key := range g.orderedKeys() {
+			response[i] = g.Map[key]
 		}
 	}
 	if g.Type == JSONArray {
@@ -202,7 +204,11 @@ func (g *GoJSON) Set(key interface{}, value *GoJSON) string {
 		if g.Map == nil {
 			g.Map = make(map[string]*GoJSON)
 		}
-		g.Map[key.(string)] = value
+		strKey := key.(string)
+		if _, found := g.Map[strKey]; !found {
+			g.keys = append(g.keys, strKey)
+		}
+		g.Map[strKey] = value
 	case JSONArray:
 		var index int
 		var ok bool
@@ -218,7 +224,107 @@ func (g *GoJSON) Set(key interface{}, value *GoJSON) string {
 			copy(g.Array[index+1:], g.Array[index:])
 			g.Array[index] = value
 		}
+	default:
+		return ""
+	}
+	if value != nil {
+		value.parent = g
 	}
-
 	return ""
 }
+
+// orderedKeys returns keys of the object in insertion order
+// keys added to Map directly, bypassing Set, are appended in sorted order
+func (g *GoJSON) orderedKeys() []string {
+	if len(g.keys) == len(g.Map) {
+		inSync := true
+		for _, key := range g.keys {
+			if _, found := g.Map[key]; !found {
+				inSync = false
+				break
+			}
+		}
+		if inSync {
+			return g.keys
+		}
+	}
+	keys := make([]string, 0, len(g.Map))
+	seen := make(map[string]bool, len(g.Map))
+	for _, key := range g.keys {
+		if _, found := g.Map[key]; found && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	known := len(keys)
+	for key := range g.Map {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys[known:])
+	return keys
+}
+
+// removeKey removes key from insertion order
+func (g *GoJSON) removeKey(key string) {
+	for i, k := range g.keys {
+		if k == key {
+			g.keys = append(g.keys[:i], g.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// detach clears parent of the node removed from g
+func (g *GoJSON) detach(node *GoJSON) {
+	if node != nil && node.parent == g {
+		node.parent = nil
+	}
+}
+
+// adopt sets g as parent of its children, it is used after Map or Array were taken from another node
+func (g *GoJSON) adopt() {
+	for _, value := range g.Map {
+		value.parent = g
+	}
+	for _, value := range g.Array {
+		value.parent = g
+	}
+}
+
+// assign makes g a copy of value keeping g's place in its tree, children are shared
+func (g *GoJSON) assign(value *GoJSON) {
+	g.Type = value.Type
+	g.Bytes = value.Bytes
+	g.Map = value.Map
+	g.keys = value.keys
+	g.Array = value.Array
+	g.adopt()
+}
+
+// Clone returns a deep copy of g, the copy isn't attached to any parent
+func (g *GoJSON) Clone() *GoJSON {
+	clone := &GoJSON{Type: g.Type}
+	if g.Bytes != nil {
+		clone.Bytes = append([]byte(nil), g.Bytes...)
+	}
+	if g.Map != nil {
+		clone.Map = make(map[string]*GoJSON, len(g.Map))
+		clone.keys = append([]string(nil), g.orderedKeys()...)
+		for key, value := range g.Map {
+			child := value.Clone()
+			child.parent = clone
+			clone.Map[key] = child
+		}
+	}
+	if g.Array != nil {
+		clone.Array = make([]*GoJSON, len(g.Array))
+		for i, value := range g.Array {
+			child := value.Clone()
+			child.parent = clone
+			clone.Array[i] = child
+		}
+	}
+	return clone
+}
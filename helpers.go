@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"gopkg.in/mgo.v2/bson"
 	"math"
-	"bytes"
+	"math/big"
 	"time"
 )
 
@@ -25,27 +25,55 @@ func (g *GoJSON) Value() ([]byte, JSONType) {
 	return g.Bytes, g.Type
 }
 
+// ValueNumber returns the original number text of the node if its Type is JSONInt or JSONFloat
+func (g *GoJSON) ValueNumber() (Number, error) {
+	if g.Type != JSONInt && g.Type != JSONFloat {
+		return "", errors.New("Type missmatch")
+	}
+	return Number(g.Bytes), nil
+}
+
 // ValueInt returns int representation of the node if its Type is JSONInt or JSONFloat
+// numbers with a fractional part return ErrNotInteger and numbers out of int range ErrOverflow
 // if node is empty and dft was specified if will be returned otherwise 0 and error
 func (g *GoJSON) ValueInt(dft ...int) (result int, err error) {
-	if g.Type != JSONInt && g.Type != JSONFloat {
-		err = errors.New("Type missmatch")
-	} else {
-		if g.Type == JSONInt {
-			result, err = strconv.Atoi(bytesToStr(g.Bytes))
-		} else {
-			if di := bytes.Index(g.Bytes, []byte(".")); di > 0 {
-				result, err = strconv.Atoi(bytesToStr(g.Bytes[:di]))
-			} else {
-				if di == 0 {
-					return 0, nil
-				} else {
-					result, err = strconv.Atoi(bytesToStr(g.Bytes))
-				}
-			}
+	var i int64
+	if i, err = g.ValueInt64(); err == nil {
+		if result = int(i); int64(result) != i {
+			result, err = 0, ErrOverflow
+		}
+	}
+	if err != nil {
+		if len(dft) > 0 {
+			return dft[0], nil
+		}
+	}
+	return
+}
 
+// ValueInt64 returns int64 representation of the node if its Type is JSONInt or JSONFloat
+// numbers with a fractional part return ErrNotInteger and numbers out of int64 range ErrOverflow
+// if node is empty and dft was specified if will be returned otherwise 0 and error
+func (g *GoJSON) ValueInt64(dft ...int64) (result int64, err error) {
+	var n Number
+	if n, err = g.ValueNumber(); err == nil {
+		result, err = n.Int64()
+	}
+	if err != nil {
+		if len(dft) > 0 {
+			return dft[0], nil
 		}
+	}
+	return
+}
 
+// ValueUint64 returns uint64 representation of the node if its Type is JSONInt or JSONFloat
+// negative numbers and numbers out of uint64 range return ErrOverflow
+// if node is empty and dft was specified if will be returned otherwise 0 and error
+func (g *GoJSON) ValueUint64(dft ...uint64) (result uint64, err error) {
+	var n Number
+	if n, err = g.ValueNumber(); err == nil {
+		result, err = n.Uint64()
 	}
 	if err != nil {
 		if len(dft) > 0 {
@@ -56,12 +84,12 @@ func (g *GoJSON) ValueInt(dft ...int) (result int, err error) {
 }
 
 // ValueFloat returns float representation of the node if its Type is JSONFloat or JSONInt
+// numbers out of float64 range return ErrOverflow
 // if node is empty and dft was specified if will be returned otherwise 0 and error
 func (g *GoJSON) ValueFloat(dft ...float64) (result float64, err error) {
-	if g.Type != JSONFloat && g.Type != JSONInt {
-		err = errors.New("Type missmatch")
-	} else {
-		result, err = strconv.ParseFloat(bytesToStr(g.Bytes), 64)
+	var n Number
+	if n, err = g.ValueNumber(); err == nil {
+		result, err = n.Float64()
 	}
 	if err != nil {
 		if len(dft) > 0 {
@@ -71,6 +99,33 @@ func (g *GoJSON) ValueFloat(dft ...float64) (result float64, err error) {
 	return
 }
 
+// ValueBigInt returns arbitrary-precision integer of the node if its Type is JSONInt or JSONFloat
+func (g *GoJSON) ValueBigInt() (*big.Int, error) {
+	n, err := g.ValueNumber()
+	if err != nil {
+		return nil, err
+	}
+	return n.BigInt()
+}
+
+// ValueBigFloat returns arbitrary-precision float of the node if its Type is JSONInt or JSONFloat
+func (g *GoJSON) ValueBigFloat() (*big.Float, error) {
+	n, err := g.ValueNumber()
+	if err != nil {
+		return nil, err
+	}
+	return n.BigFloat()
+}
+
+// ValueRat returns exact value of the node if its Type is JSONInt or JSONFloat
+func (g *GoJSON) ValueRat() (*big.Rat, error) {
+	n, err := g.ValueNumber()
+	if err != nil {
+		return nil, err
+	}
+	return n.Rat()
+}
+
 // ValueString returns string representation of the node if its Type is JSONString
 // if node is empty and dft was specified if will be returned otherwise "" and error
 func (g *GoJSON) ValueString(dft ...string) (result string, err error) {
@@ -149,13 +204,15 @@ func (g *GoJSON) setBytes(value []byte, Type JSONType) string {
 			return "invalid float"
 		}
 	case JSONArray, JSONObject:
-		child := Unmarshal(value)
-		if child == nil {
+		child, err := Parse(value)
+		if err != nil || child.Type != Type {
 			return "array or object expected"
 		}
 		g.Type = Type
 		g.Array = child.Array
 		g.Map = child.Map
+		g.keys = child.keys
+		g.adopt()
 		return ""
 	}
 	g.Type = Type
@@ -207,11 +264,16 @@ func (g *GoJSON) MarshalJSON() ([]byte, error) {
 }
 
 func (g *GoJSON) UnmarshalJSON(data []byte) error {
-	newJSON := Unmarshal(data)
+	newJSON, err := Parse(data)
+	if err != nil {
+		return err
+	}
 	g.Map = newJSON.Map
+	g.keys = newJSON.keys
 	g.Array = newJSON.Array
 	g.Type = newJSON.Type
 	g.Bytes = newJSON.Bytes
+	g.adopt()
 	return nil
 }
 
@@ -0,0 +1,186 @@
+package gojson
+
+import (
+	"sort"
+
+	"github.com/FanFani4/gojson/internal/format"
+)
+
+// inferEnumLimit is the maximum number of distinct strings InferSchema turns into enum,
+// every value has to be seen at least twice on average to tell enums from free text
+const inferEnumLimit = 10
+
+// inferredFormats are string formats InferSchema detects, in order of preference
+var inferredFormats = []string{"date-time", "uuid", "email"}
+
+// schemaTypes are JSON Schema type names in the order they are written
+var schemaTypes = []string{"null", "boolean", "integer", "number", "string", "array", "object"}
+
+// InferSchema returns JSON Schema draft 2020-12 describing all samples: types seen at every place,
+// properties present in every object as required, numeric ranges, enum for strings with few distinct values
+// and date-time, uuid or email format if every string at the place has it
+func InferSchema(samples ...*GoJSON) *GoJSON {
+	stats := &valueStats{}
+	for _, sample := range samples {
+		stats.add(sample)
+	}
+	result := NewObject()
+	result.Set("$schema", stringNode("https://json-schema.org/draft/2020-12/schema"))
+	stats.write(result)
+	return result
+}
+
+// valueStats collects values seen at one place of the samples
+type valueStats struct {
+	types map[string]bool
+
+	minimum, maximum *GoJSON
+
+	strings    int
+	distinct   map[string]bool // nil once there are more than inferEnumLimit values
+	formats    []bool          // formats[i] is set while every string has inferredFormats[i]
+	objects    int
+	keys       []string
+	properties map[string]*valueStats
+	present    map[string]int
+	items      *valueStats
+}
+
+func (s *valueStats) add(value *GoJSON) {
+	if s.types == nil {
+		s.types = make(map[string]bool)
+	}
+	switch value.Type {
+	case JSONNull:
+		s.types["null"] = true
+	case JSONBool:
+		s.types["boolean"] = true
+	case JSONInt, JSONFloat:
+		if value.Type == JSONInt {
+			s.types["integer"] = true
+		} else {
+			s.types["number"] = true
+		}
+		if s.minimum == nil || compareNumbers(value.Bytes, s.minimum.Bytes) < 0 {
+			s.minimum = value
+		}
+		if s.maximum == nil || compareNumbers(value.Bytes, s.maximum.Bytes) > 0 {
+			s.maximum = value
+		}
+	case JSONString:
+		s.types["string"] = true
+		if s.strings == 0 {
+			s.distinct = make(map[string]bool)
+			s.formats = make([]bool, len(inferredFormats))
+			for i := range s.formats {
+				s.formats[i] = true
+			}
+		}
+		s.strings++
+		if s.distinct != nil {
+			s.distinct[string(value.Bytes)] = true
+			if len(s.distinct) > inferEnumLimit {
+				s.distinct = nil
+			}
+		}
+		for i, name := range inferredFormats {
+			if s.formats[i] {
+				s.formats[i], _ = format.Check(name, string(value.Bytes))
+			}
+		}
+	case JSONArray:
+		s.types["array"] = true
+		if s.items == nil {
+			s.items = &valueStats{}
+		}
+		for _, item := range value.Array {
+			s.items.add(item)
+		}
+	case JSONObject:
+		s.types["object"] = true
+		if s.properties == nil {
+			s.properties = make(map[string]*valueStats)
+			s.present = make(map[string]int)
+		}
+		s.objects++
+		for _, key := range value.Keys() {
+			property, found := s.properties[key]
+			if !found {
+				property = &valueStats{}
+				s.properties[key] = property
+				s.keys = append(s.keys, key)
+			}
+			property.add(value.Map[key])
+			s.present[key]++
+		}
+	}
+}
+
+// write sets schema keywords describing collected values
+func (s *valueStats) write(schema *GoJSON) {
+	var types []string
+	for _, typ := range schemaTypes {
+		if s.types[typ] && !(typ == "integer" && s.types["number"]) {
+			types = append(types, typ)
+		}
+	}
+	switch len(types) {
+	case 0:
+		return
+	case 1:
+		schema.Set("type", stringNode(types[0]))
+	default:
+		schema.Set("type", stringArray(types))
+	}
+
+	if s.strings > 0 {
+		if s.distinct != nil && s.strings >= 2*len(s.distinct) && len(types) == 1 {
+			values := make([]string, 0, len(s.distinct))
+			for value := range s.distinct {
+				values = append(values, value)
+			}
+			sort.Strings(values)
+			schema.Set("enum", stringArray(values))
+		} else {
+			for i, name := range inferredFormats {
+				if s.formats[i] {
+					schema.Set("format", stringNode(name))
+					break
+				}
+			}
+		}
+	}
+	if s.minimum != nil {
+		schema.Set("minimum", s.minimum.Clone())
+		schema.Set("maximum", s.maximum.Clone())
+	}
+	if s.items != nil && s.items.types != nil {
+		items := NewObject()
+		s.items.write(items)
+		schema.Set("items", items)
+	}
+	if s.properties != nil {
+		properties := NewObject()
+		var required []string
+		for _, key := range s.keys {
+			property := NewObject()
+			s.properties[key].write(property)
+			properties.Set(key, property)
+			if s.present[key] == s.objects {
+				required = append(required, key)
+			}
+		}
+		schema.Set("properties", properties)
+		if required != nil {
+			schema.Set("required", stringArray(required))
+		}
+	}
+}
+
+func stringArray(values []string) *GoJSON {
+	array := NewArray()
+	for _, value := range values {
+		array.Set(-1, stringNode(value))
+	}
+	return array
+}
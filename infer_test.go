@@ -0,0 +1,56 @@
+package gojson
+
+import (
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+	samples := []string{
+		`{"id": "123e4567-e89b-12d3-a456-426614174000", "status": "new", "price": 10, "created": "2024-01-02T10:00:00Z",
+			"contact": {"email": "a@b.com", "phone": null}, "items": [{"sku": "A", "qty": 1}], "note": "call me"}`,
+		`{"id": "00000000-0000-0000-0000-000000000000", "status": "done", "price": 2.5, "created": "2024-03-04T00:00:00+02:00",
+			"contact": {"email": "c@d.org", "phone": "123"}, "items": [{"sku": "B", "qty": 3}, {"sku": "C"}]}`,
+		`{"id": "123e4567-e89b-12d3-a456-426614174001", "status": "new", "price": -1, "created": "2024-05-06T07:08:09.5Z",
+			"contact": {"email": "e@f.net"}, "items": [], "note": "leave at the door"}`,
+		`{"id": "123e4567-e89b-12d3-a456-426614174002", "status": "done", "price": 100, "created": "2024-05-06T07:08:09Z",
+			"contact": {"email": "g@h.io", "phone": "456"}, "items": [{"sku": "D", "qty": 2.0}], "note": 1}`,
+	}
+	var docs []*GoJSON
+	for _, sample := range samples {
+		docs = append(docs, Unmarshal([]byte(sample)))
+	}
+	expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+		`"id":{"type":"string","format":"uuid"},` +
+		`"status":{"type":"string","enum":["done","new"]},` +
+		`"price":{"type":"number","minimum":-1,"maximum":100},` +
+		`"created":{"type":"string","format":"date-time"},` +
+		`"contact":{"type":"object","properties":{"email":{"type":"string","format":"email"},"phone":{"type":["null","string"]}},"required":["email"]},` +
+		`"items":{"type":"array","items":{"type":"object","properties":{"sku":{"type":"string"},"qty":{"type":"number","minimum":1,"maximum":3}},"required":["sku"]}},` +
+		`"note":{"type":["integer","string"],"minimum":1,"maximum":1}},` +
+		`"required":["id","status","price","created","contact","items"]}`
+	schema := InferSchema(docs...)
+	if out := string(schema.Marshal()); out != expected {
+		t.Fatalf("expected %s\ngot      %s", expected, out)
+	}
+	if p := schema.Get("properties").Get("contact").Get("required").Get(0).Pointer(); p != "/properties/contact/required/0" {
+		t.Fatal("wrong pointer", p)
+	}
+
+	cases := map[string]string{
+		``:             `{"$schema":"https://json-schema.org/draft/2020-12/schema"}`,
+		`1`:            `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"integer","minimum":1,"maximum":1}`,
+		`[[], [true]]`: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"array","items":{"type":"array","items":{"type":"boolean"}}}`,
+		`"a"`:          `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"string"}`,
+		`"user@host"`:  `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"string","format":"email"}`,
+		`{}`:           `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{}}`,
+	}
+	for sample, expected := range cases {
+		var docs []*GoJSON
+		if sample != "" {
+			docs = append(docs, Unmarshal([]byte(sample)))
+		}
+		if out := string(InferSchema(docs...).Marshal()); out != expected {
+			t.Fatalf("%s: expected %s, got %s", sample, expected, out)
+		}
+	}
+}
@@ -0,0 +1,498 @@
+package gojson
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Path is a compiled RFC 9535 JSONPath query, it is safe for concurrent use and reusable across documents
+type Path struct {
+	expr  string
+	query *pathQuery
+}
+
+// CompilePath parses JSONPath expression like "$.store.book[?@.price < 10].title"
+func CompilePath(expr string) (*Path, error) {
+	p := pathParser{expr: expr}
+	query, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Path{expr: expr, query: query}, nil
+}
+
+// MustCompilePath is like CompilePath but panics if the expression is invalid
+func MustCompilePath(expr string) *Path {
+	path, err := CompilePath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// String returns the source expression
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Query returns nodes of g selected by the path, nodes are not copied
+func (p *Path) Query(g *GoJSON) []*GoJSON {
+	return p.query.eval(g, g)
+}
+
+// Query compiles JSONPath expression and returns selected nodes, nodes are not copied
+// use CompilePath to run the same expression over many documents
+func (g *GoJSON) Query(expr string) ([]*GoJSON, error) {
+	path, err := CompilePath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return path.Query(g), nil
+}
+
+// region segments
+
+// pathQuery is absolute ($) or relative (@) query
+type pathQuery struct {
+	relative bool
+	segments []*pathSegment
+}
+
+type pathSegment struct {
+	descendant bool
+	selectors  []pathSelector
+}
+
+// pathSelector appends nodes selected from node to out
+type pathSelector interface {
+	selectNodes(root, node *GoJSON, out []*GoJSON) []*GoJSON
+}
+
+func (q *pathQuery) eval(root, current *GoJSON) []*GoJSON {
+	nodes := []*GoJSON{root}
+	if q.relative {
+		nodes[0] = current
+	}
+	for _, segment := range q.segments {
+		var out []*GoJSON
+		for _, node := range nodes {
+			if segment.descendant {
+				out = segment.selectDescendants(root, node, out)
+			} else {
+				for _, selector := range segment.selectors {
+					out = selector.selectNodes(root, node, out)
+				}
+			}
+		}
+		nodes = out
+	}
+	return nodes
+}
+
+// singular reports whether query selects at most one node
+func (q *pathQuery) singular() bool {
+	for _, segment := range q.segments {
+		if segment.descendant || len(segment.selectors) != 1 {
+			return false
+		}
+		switch segment.selectors[0].(type) {
+		case nameSelector, indexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// selectDescendants applies selectors to node and all its descendants, parents first
+func (s *pathSegment) selectDescendants(root, node *GoJSON, out []*GoJSON) []*GoJSON {
+	for _, selector := range s.selectors {
+		out = selector.selectNodes(root, node, out)
+	}
+	for _, child := range children(node) {
+		out = s.selectDescendants(root, child, out)
+	}
+	return out
+}
+
+// children returns values of object in key order or elements of array
+func children(node *GoJSON) []*GoJSON {
+	switch node.Type {
+	case JSONObject:
+		return node.Values()
+	case JSONArray:
+		return node.Array
+	}
+	return nil
+}
+
+type nameSelector string
+
+func (s nameSelector) selectNodes(root, node *GoJSON, out []*GoJSON) []*GoJSON {
+	if node.Type == JSONObject {
+		if value, found := node.Map[string(s)]; found {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+type wildcardSelector struct{}
+
+func (wildcardSelector) selectNodes(root, node *GoJSON, out []*GoJSON) []*GoJSON {
+	return append(out, children(node)...)
+}
+
+type indexSelector int64
+
+func (s indexSelector) selectNodes(root, node *GoJSON, out []*GoJSON) []*GoJSON {
+	if node.Type != JSONArray {
+		return out
+	}
+	i := int64(s)
+	if i < 0 {
+		i += int64(len(node.Array))
+	}
+	if i >= 0 && i < int64(len(node.Array)) {
+		out = append(out, node.Array[i])
+	}
+	return out
+}
+
+type sliceSelector struct {
+	start, end       int64
+	hasStart, hasEnd bool
+	step             int64
+}
+
+func (s sliceSelector) selectNodes(root, node *GoJSON, out []*GoJSON) []*GoJSON {
+	if node.Type != JSONArray || s.step == 0 {
+		return out
+	}
+	length := int64(len(node.Array))
+	normalize := func(i int64) int64 {
+		if i < 0 {
+			return length + i
+		}
+		return i
+	}
+	clamp := func(i, lower, upper int64) int64 {
+		if i < lower {
+			return lower
+		}
+		if i > upper {
+			return upper
+		}
+		return i
+	}
+	if s.step > 0 {
+		start, end := int64(0), length
+		if s.hasStart {
+			start = clamp(normalize(s.start), 0, length)
+		}
+		if s.hasEnd {
+			end = clamp(normalize(s.end), 0, length)
+		}
+		for i := start; i < end; i += s.step {
+			out = append(out, node.Array[i])
+		}
+		return out
+	}
+	start, end := length-1, int64(-1)
+	if s.hasStart {
+		start = clamp(normalize(s.start), -1, length-1)
+	}
+	if s.hasEnd {
+		end = clamp(normalize(s.end), -1, length-1)
+	}
+	for i := start; i > end; i += s.step {
+		out = append(out, node.Array[i])
+	}
+	return out
+}
+
+type filterSelector struct {
+	expr logicalExpr
+}
+
+func (s filterSelector) selectNodes(root, node *GoJSON, out []*GoJSON) []*GoJSON {
+	for _, child := range children(node) {
+		if s.expr.test(root, child) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+// endregion
+
+// region filter expressions
+
+// logicalExpr is an expression of filter selector
+type logicalExpr interface {
+	test(root, current *GoJSON) bool
+}
+
+type orExpr []logicalExpr
+
+func (e orExpr) test(root, current *GoJSON) bool {
+	for _, expr := range e {
+		if expr.test(root, current) {
+			return true
+		}
+	}
+	return false
+}
+
+type andExpr []logicalExpr
+
+func (e andExpr) test(root, current *GoJSON) bool {
+	for _, expr := range e {
+		if !expr.test(root, current) {
+			return false
+		}
+	}
+	return true
+}
+
+type notExpr struct {
+	expr logicalExpr
+}
+
+func (e notExpr) test(root, current *GoJSON) bool {
+	return !e.expr.test(root, current)
+}
+
+// existsExpr is a query used as test, it is true if query selects anything
+type existsExpr struct {
+	query *pathQuery
+}
+
+func (e existsExpr) test(root, current *GoJSON) bool {
+	return len(e.query.eval(root, current)) > 0
+}
+
+// functionTest is a function returning logical value used as test
+type functionTest struct {
+	call *functionCall
+}
+
+func (e functionTest) test(root, current *GoJSON) bool {
+	return e.call.logical(root, current)
+}
+
+type compareExpr struct {
+	op          string
+	left, right valueExpr
+}
+
+func (e compareExpr) test(root, current *GoJSON) bool {
+	left, right := e.left.value(root, current), e.right.value(root, current)
+	switch e.op {
+	case "==":
+		return pathEqual(left, right)
+	case "!=":
+		return !pathEqual(left, right)
+	case "<":
+		return pathLess(left, right)
+	case ">":
+		return pathLess(right, left)
+	case "<=":
+		return pathLess(left, right) || pathEqual(left, right)
+	case ">=":
+		return pathLess(right, left) || pathEqual(left, right)
+	}
+	return false
+}
+
+// valueExpr is a comparable: literal, singular query or function returning value
+// nil value means Nothing
+type valueExpr interface {
+	value(root, current *GoJSON) *GoJSON
+}
+
+type literalValue struct {
+	node *GoJSON
+}
+
+func (v literalValue) value(root, current *GoJSON) *GoJSON {
+	return v.node
+}
+
+type singularQuery struct {
+	query *pathQuery
+}
+
+func (v singularQuery) value(root, current *GoJSON) *GoJSON {
+	if nodes := v.query.eval(root, current); len(nodes) == 1 {
+		return nodes[0]
+	}
+	return nil
+}
+
+// pathEqual compares values as RFC 9535 requires, Nothing is equal only to Nothing
+func pathEqual(a, b *GoJSON) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return nodesEqual(a, b)
+}
+
+// pathLess is defined for pairs of numbers and pairs of strings only
+func pathLess(a, b *GoJSON) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if isNumber(a) && isNumber(b) {
+		return compareNumbers(a.Bytes, b.Bytes) < 0
+	}
+	if a.Type == JSONString && b.Type == JSONString {
+		// UTF-8 byte order is the same as code point order
+		return bytesToStr(a.Bytes) < bytesToStr(b.Bytes)
+	}
+	return false
+}
+
+func isNumber(g *GoJSON) bool {
+	return g.Type == JSONInt || g.Type == JSONFloat
+}
+
+// compareNumbers compares number texts by value, exactly when possible
+func compareNumbers(a, b []byte) int {
+	if string(a) == string(b) {
+		return 0
+	}
+	ra, errA := Number(a).Rat()
+	rb, errB := Number(b).Rat()
+	if errA == nil && errB == nil {
+		return ra.Cmp(rb)
+	}
+	fa, _ := Number(a).BigFloat()
+	fb, _ := Number(b).BigFloat()
+	if fa == nil || fb == nil {
+		return strings.Compare(string(a), string(b))
+	}
+	return fa.Cmp(fb)
+}
+
+// endregion
+
+// region functions
+
+// functionType is RFC 9535 type of function result or parameter
+type functionType int
+
+const (
+	valueType functionType = iota
+	logicalType
+	nodesType
+)
+
+type pathFunction struct {
+	result functionType
+	params []functionType
+}
+
+var pathFunctions = map[string]pathFunction{
+	"length": {valueType, []functionType{valueType}},
+	"count":  {valueType, []functionType{nodesType}},
+	"match":  {logicalType, []functionType{valueType, valueType}},
+	"search": {logicalType, []functionType{valueType, valueType}},
+	"value":  {valueType, []functionType{nodesType}},
+}
+
+// functionArg is value argument or query argument of nodes type
+type functionArg struct {
+	value valueExpr
+	query *pathQuery
+}
+
+type functionCall struct {
+	name         string
+	args         []functionArg
+	constPattern bool           // pattern of match or search is a string literal
+	re           *regexp.Regexp // compiled constant pattern, nil if it is invalid
+}
+
+func (f *functionCall) value(root, current *GoJSON) *GoJSON {
+	switch f.name {
+	case "length":
+		arg := f.args[0].value.value(root, current)
+		if arg == nil {
+			return nil
+		}
+		switch arg.Type {
+		case JSONString:
+			return numberNode(utf8.RuneCount(arg.Bytes))
+		case JSONArray:
+			return numberNode(len(arg.Array))
+		case JSONObject:
+			return numberNode(len(arg.Map))
+		}
+		return nil
+	case "count":
+		return numberNode(len(f.args[0].query.eval(root, current)))
+	case "value":
+		if nodes := f.args[0].query.eval(root, current); len(nodes) == 1 {
+			return nodes[0]
+		}
+	}
+	return nil
+}
+
+func (f *functionCall) logical(root, current *GoJSON) bool {
+	str := f.args[0].value.value(root, current)
+	if str == nil || str.Type != JSONString {
+		return false
+	}
+	re := f.re
+	if !f.constPattern {
+		pattern := f.args[1].value.value(root, current)
+		if pattern == nil || pattern.Type != JSONString {
+			return false
+		}
+		re = compileIRegexp(bytesToStr(pattern.Bytes), f.name == "match")
+	}
+	return re != nil && re.Match(str.Bytes)
+}
+
+func numberNode(n int) *GoJSON {
+	return &GoJSON{Type: JSONInt, Bytes: []byte(strconv.Itoa(n))}
+}
+
+// compileIRegexp translates RFC 9485 I-Regexp to Go regexp, nil is returned for invalid patterns
+// "." of I-Regexp doesn't match line breaks
+func compileIRegexp(pattern string, full bool) *regexp.Regexp {
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			b.WriteByte(c)
+			i++
+			c = pattern[i]
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '.' && !inClass:
+			b.WriteString(`[^\n\r]`)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	expr := b.String()
+	if full {
+		expr = `^(?:` + expr + `)$`
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// endregion
@@ -0,0 +1,446 @@
+package gojson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxPathInt is the I-JSON range of integers allowed as indexes and slice bounds
+const maxPathInt = 1<<53 - 1
+
+// PathError describes invalid JSONPath expression
+type PathError struct {
+	Expr   string
+	Offset int // byte offset of the error in Expr
+	Msg    string
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("invalid jsonpath %q at offset %d: %s", e.Expr, e.Offset, e.Msg)
+}
+
+// pathParser is recursive descent parser of RFC 9535 grammar, like json parser it panics with the error
+type pathParser struct {
+	expr string
+	pos  int
+}
+
+// pathOperand is operand of comparison or test expression, only one of the fields is set
+type pathOperand struct {
+	pos     int
+	query   *pathQuery
+	call    *functionCall
+	literal *GoJSON
+}
+
+func (p *pathParser) fail(msg string) {
+	panic(&PathError{Expr: p.expr, Offset: p.pos, Msg: msg})
+}
+
+func (p *pathParser) parse() (query *pathQuery, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pathErr, ok := r.(*PathError)
+			if !ok {
+				panic(r)
+			}
+			err = pathErr
+		}
+	}()
+	if !p.consume('$') {
+		p.fail("expected '$'")
+	}
+	query = p.parseSegments(false)
+	if p.pos != len(p.expr) {
+		p.fail("unexpected character")
+	}
+	return query, nil
+}
+
+func (p *pathParser) peek() byte {
+	if p.pos < len(p.expr) {
+		return p.expr[p.pos]
+	}
+	return 0
+}
+
+func (p *pathParser) consume(c byte) bool {
+	if p.peek() == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *pathParser) expect(c byte) {
+	if !p.consume(c) {
+		p.fail(fmt.Sprintf("expected '%c'", c))
+	}
+}
+
+// skipBlank skips RFC 9535 blank space
+func (p *pathParser) skipBlank() {
+	for p.pos < len(p.expr) {
+		switch p.expr[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *pathParser) parseSegments(relative bool) *pathQuery {
+	query := &pathQuery{relative: relative}
+	for {
+		start := p.pos
+		p.skipBlank()
+		segment := &pathSegment{}
+		switch {
+		case strings.HasPrefix(p.expr[p.pos:], ".."):
+			p.pos += 2
+			segment.descendant = true
+			if p.peek() == '[' {
+				segment.selectors = p.parseBracket()
+			} else {
+				segment.selectors = []pathSelector{p.parseShorthand()}
+			}
+		case p.peek() == '.':
+			p.pos++
+			segment.selectors = []pathSelector{p.parseShorthand()}
+		case p.peek() == '[':
+			segment.selectors = p.parseBracket()
+		default:
+			// blank space belongs to whatever follows the query
+			p.pos = start
+			return query
+		}
+		query.segments = append(query.segments, segment)
+	}
+}
+
+// parseShorthand parses wildcard or member name after dot
+func (p *pathParser) parseShorthand() pathSelector {
+	if p.consume('*') {
+		return wildcardSelector{}
+	}
+	start := p.pos
+	for p.pos < len(p.expr) {
+		r, size := utf8.DecodeRuneInString(p.expr[p.pos:])
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= 0x80 && r != utf8.RuneError ||
+			p.pos > start && r >= '0' && r <= '9') {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		p.fail("expected member name or '*'")
+	}
+	return nameSelector(p.expr[start:p.pos])
+}
+
+func (p *pathParser) parseBracket() []pathSelector {
+	p.expect('[')
+	var selectors []pathSelector
+	for {
+		p.skipBlank()
+		selectors = append(selectors, p.parseSelector())
+		p.skipBlank()
+		if p.consume(']') {
+			return selectors
+		}
+		if !p.consume(',') {
+			p.fail("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *pathParser) parseSelector() pathSelector {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		return nameSelector(p.parseString())
+	case c == '*':
+		p.pos++
+		return wildcardSelector{}
+	case c == '?':
+		p.pos++
+		p.skipBlank()
+		return filterSelector{expr: p.parseOr()}
+	}
+
+	start, hasStart := p.parseInt()
+	p.skipBlank()
+	if !p.consume(':') {
+		if !hasStart {
+			p.fail("expected selector")
+		}
+		return indexSelector(start)
+	}
+	s := sliceSelector{start: start, hasStart: hasStart, step: 1}
+	p.skipBlank()
+	s.end, s.hasEnd = p.parseInt()
+	p.skipBlank()
+	if p.consume(':') {
+		p.skipBlank()
+		if step, hasStep := p.parseInt(); hasStep {
+			s.step = step
+		}
+	}
+	return s
+}
+
+// parseInt parses optional integer without leading zeros within I-JSON range
+func (p *pathParser) parseInt() (int64, bool) {
+	start := p.pos
+	p.consume('-')
+	digits := p.pos
+	for p.pos < len(p.expr) && isDigit(p.expr[p.pos]) {
+		p.pos++
+	}
+	if p.pos == digits {
+		if digits != start {
+			p.fail("expected digit")
+		}
+		return 0, false
+	}
+	text := p.expr[start:p.pos]
+	if p.expr[digits] == '0' && (p.pos-digits > 1 || digits != start) {
+		p.pos = start
+		p.fail("invalid integer " + text)
+	}
+	i, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || i > maxPathInt || i < -maxPathInt {
+		p.pos = start
+		p.fail("integer out of range " + text)
+	}
+	return i, true
+}
+
+// parseString parses single or double quoted string literal
+func (p *pathParser) parseString() string {
+	quote := p.expr[p.pos]
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String()
+		case c == '\\':
+			r, next, err := decodeEscape(p.expr, p.pos, quote)
+			if p.pos = next; err != nil {
+				p.fail(err.Error())
+			}
+			b.WriteRune(r)
+			continue
+		case c < 0x20:
+			p.fail("control character in string")
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	p.fail("unterminated string")
+	return ""
+}
+
+// region filter expressions
+
+func (p *pathParser) parseOr() logicalExpr {
+	exprs := orExpr{p.parseAnd()}
+	for {
+		start := p.pos
+		p.skipBlank()
+		if !strings.HasPrefix(p.expr[p.pos:], "||") {
+			p.pos = start
+			break
+		}
+		p.pos += 2
+		p.skipBlank()
+		exprs = append(exprs, p.parseAnd())
+	}
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return exprs
+}
+
+func (p *pathParser) parseAnd() logicalExpr {
+	exprs := andExpr{p.parseBasic()}
+	for {
+		start := p.pos
+		p.skipBlank()
+		if !strings.HasPrefix(p.expr[p.pos:], "&&") {
+			p.pos = start
+			break
+		}
+		p.pos += 2
+		p.skipBlank()
+		exprs = append(exprs, p.parseBasic())
+	}
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return exprs
+}
+
+func (p *pathParser) parseBasic() logicalExpr {
+	if p.consume('!') {
+		p.skipBlank()
+		if p.peek() == '(' {
+			return notExpr{p.parseParen()}
+		}
+		return notExpr{p.asTest(p.parseOperand())}
+	}
+	if p.peek() == '(' {
+		return p.parseParen()
+	}
+	left := p.parseOperand()
+	start := p.pos
+	p.skipBlank()
+	op := p.parseCompareOp()
+	if op == "" {
+		p.pos = start
+		return p.asTest(left)
+	}
+	p.skipBlank()
+	right := p.parseOperand()
+	return compareExpr{op: op, left: p.asComparable(left), right: p.asComparable(right)}
+}
+
+func (p *pathParser) parseParen() logicalExpr {
+	p.expect('(')
+	p.skipBlank()
+	expr := p.parseOr()
+	p.skipBlank()
+	p.expect(')')
+	return expr
+}
+
+func (p *pathParser) parseCompareOp() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(p.expr[p.pos:], op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *pathParser) parseOperand() pathOperand {
+	operand := pathOperand{pos: p.pos}
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		operand.query = p.parseSegments(c == '@')
+	case c == '\'' || c == '"':
+		operand.literal = &GoJSON{Type: JSONString, Bytes: []byte(p.parseString())}
+	case c == '-' || isDigit(c):
+		operand.literal = p.parseNumber()
+	case c >= 'a' && c <= 'z':
+		for _, literal := range []string{"true", "false", "null"} {
+			if strings.HasPrefix(p.expr[p.pos:], literal) && !isFunctionNameChar(p.expr, p.pos+len(literal)) {
+				p.pos += len(literal)
+				operand.literal = Unmarshal([]byte(literal))
+				return operand
+			}
+		}
+		operand.call = p.parseFunction()
+	default:
+		p.fail("expected query, literal or function")
+	}
+	return operand
+}
+
+// parseNumber parses number literal, "-0" is allowed unlike in indexes
+func (p *pathParser) parseNumber() *GoJSON {
+	start := p.pos
+	for p.pos < len(p.expr) && strings.IndexByte("-+.eE0123456789", p.expr[p.pos]) >= 0 {
+		p.pos++
+	}
+	node, err := Parse([]byte(p.expr[start:p.pos]), ParseOptions{Strict: true})
+	if err != nil {
+		p.pos = start
+		p.fail("invalid number")
+	}
+	return node
+}
+
+func isFunctionNameChar(s string, i int) bool {
+	return i < len(s) && (s[i] >= 'a' && s[i] <= 'z' || s[i] == '_' || isDigit(s[i]))
+}
+
+func (p *pathParser) parseFunction() *functionCall {
+	start := p.pos
+	for isFunctionNameChar(p.expr, p.pos) {
+		p.pos++
+	}
+	name := p.expr[start:p.pos]
+	function, found := pathFunctions[name]
+	if !found {
+		p.pos = start
+		p.fail("unknown function " + name)
+	}
+	p.expect('(')
+	call := &functionCall{name: name}
+	for i, param := range function.params {
+		p.skipBlank()
+		if i > 0 {
+			p.expect(',')
+			p.skipBlank()
+		}
+		operand := p.parseOperand()
+		if param == nodesType {
+			if operand.query == nil {
+				p.pos = operand.pos
+				p.fail(name + " expects query argument")
+			}
+			call.args = append(call.args, functionArg{query: operand.query})
+		} else {
+			call.args = append(call.args, functionArg{value: p.asComparable(operand)})
+		}
+	}
+	p.skipBlank()
+	p.expect(')')
+	if name == "match" || name == "search" {
+		if pattern, ok := call.args[1].value.(literalValue); ok && pattern.node.Type == JSONString {
+			call.constPattern = true
+			call.re = compileIRegexp(string(pattern.node.Bytes), name == "match")
+		}
+	}
+	return call
+}
+
+// asComparable checks that operand has a value: literal, singular query or function returning value
+func (p *pathParser) asComparable(operand pathOperand) valueExpr {
+	switch {
+	case operand.literal != nil:
+		return literalValue{operand.literal}
+	case operand.query != nil:
+		if operand.query.singular() {
+			return singularQuery{operand.query}
+		}
+	case pathFunctions[operand.call.name].result == valueType:
+		return operand.call
+	}
+	p.pos = operand.pos
+	p.fail("expected literal, singular query or function returning value")
+	return nil
+}
+
+// asTest checks that operand can be used as test: query or function returning logical value
+func (p *pathParser) asTest(operand pathOperand) logicalExpr {
+	switch {
+	case operand.query != nil:
+		return existsExpr{operand.query}
+	case operand.call != nil && pathFunctions[operand.call.name].result == logicalType:
+		return functionTest{operand.call}
+	}
+	p.pos = operand.pos
+	p.fail("expected comparison, query or function returning logical value")
+	return nil
+}
+
+// endregion
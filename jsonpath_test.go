@@ -0,0 +1,141 @@
+package gojson
+
+import (
+	"bytes"
+	"testing"
+)
+
+var store = []byte(`{ "store": {
+    "book": [
+      { "category": "reference",
+        "author": "Nigel Rees",
+        "title": "Sayings of the Century",
+        "price": 8.95
+      },
+      { "category": "fiction",
+        "author": "Evelyn Waugh",
+        "title": "Sword of Honour",
+        "price": 12.99
+      },
+      { "category": "fiction",
+        "author": "Herman Melville",
+        "title": "Moby Dick",
+        "isbn": "0-553-21311-3",
+        "price": 8.99
+      },
+      { "category": "fiction",
+        "author": "J. R. R. Tolkien",
+        "title": "The Lord of the Rings",
+        "isbn": "0-395-19395-8",
+        "price": 22.99
+      }
+    ],
+    "bicycle": {
+      "color": "red",
+      "price": 399
+    }
+  }
+}`)
+
+// joinNodes marshals query result as json array
+func joinNodes(nodes []*GoJSON) string {
+	var bf bytes.Buffer
+	bf.WriteByte('[')
+	for i, node := range nodes {
+		if i > 0 {
+			bf.WriteByte(',')
+		}
+		node.MarshalWith(MarshalOptions{}, &bf)
+	}
+	bf.WriteByte(']')
+	return bf.String()
+}
+
+func TestGoJSON_Query(t *testing.T) {
+	json := Unmarshal(store)
+	cases := map[string]string{
+		`$.store.book[*].author`:                           `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`,
+		`$..author`:                                        `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`,
+		`$.store.*.color`:                                  `["red"]`,
+		`$.store..price`:                                   `[8.95,12.99,8.99,22.99,399]`,
+		`$..book[2].title`:                                 `["Moby Dick"]`,
+		`$..book[-1].title`:                                `["The Lord of the Rings"]`,
+		`$..book[0,1].title`:                               `["Sayings of the Century","Sword of Honour"]`,
+		`$..book[:2].title`:                                `["Sayings of the Century","Sword of Honour"]`,
+		`$..book[::-2].title`:                              `["The Lord of the Rings","Sword of Honour"]`,
+		`$..book[1:3:1].price`:                             `[12.99,8.99]`,
+		`$..book[?@.isbn].title`:                           `["Moby Dick","The Lord of the Rings"]`,
+		`$..book[?@.price<10].title`:                       `["Sayings of the Century","Moby Dick"]`,
+		`$..book[? !@.isbn && @.price > 10].title`:         `["Sword of Honour"]`,
+		`$.store.book[?@.price == 8.95].author`:            `["Nigel Rees"]`,
+		`$..book[?@.price == $.store.book[0].price].title`: `["Sayings of the Century"]`,
+		`$..book[?length(@.author) == 10].author`:          `["Nigel Rees"]`,
+		`$..book[?match(@.author, 'J.*')].author`:          `["J. R. R. Tolkien"]`,
+		`$..book[?search(@.title, "[Dd]ick")].author`:      `["Herman Melville"]`,
+		`$..book[?count(@.*) == 5].title`:                  `["Moby Dick","The Lord of the Rings"]`,
+		`$.store[?value(@..color) == "red"].price`:         `[399]`,
+		`$.store['bicycle']["color"]`:                      `["red"]`,
+		`$.store.book[?(@.price < 9 || @.price > 20) && @.category == 'fiction'].title`: `["Moby Dick","The Lord of the Rings"]`,
+		`$.store.bicycle[?@ == 399]`:             `[399]`,
+		`$.store.book[9]`:                        `[]`,
+		`$ .store ['bicycle'] . price`:           ``,
+		`$.store .bicycle [ 'color' , "price" ]`: `["red",399]`,
+	}
+	for expr, expected := range cases {
+		nodes, err := json.Query(expr)
+		if expected == "" {
+			if err == nil {
+				t.Fatalf("%s: expected error", expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		if out := joinNodes(nodes); out != expected {
+			t.Fatalf("%s: expected %s, got %s", expr, expected, out)
+		}
+	}
+}
+
+func TestCompilePath(t *testing.T) {
+	invalid := []string{
+		``, `$.`, `$[`, `$[01]`, `$[-0]`, `$[9007199254740992]`, `$..`, `$[?@.a == ]`,
+		`$[?length(@.*) == 1]`, `$[?length(@)]`, `$[?count(1) == 1]`, `$[?true]`, `$[?@.a == 1 == 2]`,
+		`$[?foo(@)]`, `$['\a']`, `$["\ud800"]`, `$ `, `$[?!@.a == 1]`, `$.a b`,
+	}
+	for _, expr := range invalid {
+		if _, err := CompilePath(expr); err == nil {
+			t.Fatalf("%q: expected error", expr)
+		}
+	}
+
+	path := MustCompilePath(`$[?@.a >= 2].a`)
+	for _, doc := range []string{`[{"a": 1}, {"a": 2}, {"a": 3.0}]`, `{"x": {"a": 2}, "y": {"a": "2"}, "z": {"a": 3e0}}`} {
+		if out := joinNodes(path.Query(Unmarshal([]byte(doc)))); out != `[2,3.0]` && out != `[2,3e0]` {
+			t.Fatal("wrong result", out)
+		}
+	}
+	json := Unmarshal([]byte(`{"a": {"b": null}, "c": [{"d": "é😀"}, {"d": "x\ny"}, {}]}`))
+	cases := map[string]string{
+		`$.c[?length(@.d) == 2].d`: `["é😀"]`,
+		`$.c[?match(@.d, 'x.y')]`:  `[]`,
+		`$.c[?@.d == $.missing]`:   `[{}]`,
+		`$.c[?@.d != "x\ny"].d`:    `["é😀"]`,
+		`$..*`:                     `[{"b":null},[{"d":"é😀"},{"d":"x\ny"},{}],null,{"d":"é😀"},{"d":"x\ny"},{},"é😀","x\ny"]`,
+		`$.a[?@ == null]`:          `[null]`,
+		`$.c[0]['d', 'd']`:         `["é😀","é😀"]`,
+		`$.c[5:0:-1]`:              `[{},{"d":"x\ny"}]`,
+		`$.c[?@.d > 'a'].d`:        `["é😀","x\ny"]`,
+		`$.c[?@.d < 1]`:            `[]`,
+	}
+	for expr, expected := range cases {
+		nodes, err := json.Query(expr)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		if out := joinNodes(nodes); out != expected {
+			t.Fatalf("%s: expected %s, got %s", expr, expected, out)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package gojson
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// LineError reports a malformed line of newline delimited json
+type LineError struct {
+	Line int // line number, starting from 1
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error, usually *SyntaxError
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LineReader reads newline delimited json (NDJSON, JSON Lines), one value per line
+// empty lines are ignored
+type LineReader struct {
+	// SkipInvalid skips malformed lines instead of returning *LineError, they are counted in Skipped
+	SkipInvalid bool
+	// Skipped is the number of malformed lines skipped so far
+	Skipped int
+	// ReuseBuffer parses every line in the same buffer to avoid an allocation per line,
+	// json returned by Read is valid only until the next call to Read
+	ReuseBuffer bool
+
+	r    *bufio.Reader
+	opts ParseOptions
+	line int
+	buf  []byte
+}
+
+// NewLineReader returns a reader of newline delimited json from r, opts are the same as for Parse
+func NewLineReader(r io.Reader, opts ...ParseOptions) *LineReader {
+	lr := &LineReader{r: bufio.NewReader(r)}
+	if len(opts) > 0 {
+		lr.opts = opts[0]
+	}
+	return lr
+}
+
+// Line returns number of the last line read
+func (lr *LineReader) Line() int {
+	return lr.line
+}
+
+// Read returns json of the next line, io.EOF is returned at the end of input
+// malformed lines are returned as *LineError unless SkipInvalid is set
+func (lr *LineReader) Read() (*GoJSON, error) {
+	for {
+		line, err := lr.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if len(skip(line)) == 0 {
+			continue
+		}
+		if !lr.ReuseBuffer {
+			// tree references input bytes, so they must not be shared with the buffer
+			line = append([]byte(nil), line...)
+		}
+		json, err := parseWhole(line, lr.opts)
+		if err == nil {
+			return json, nil
+		}
+		if !lr.SkipInvalid {
+			return nil, &LineError{Line: lr.line, Err: err}
+		}
+		lr.Skipped++
+	}
+}
+
+// readLine returns the next line without line ending, it is valid until the next call
+func (lr *LineReader) readLine() ([]byte, error) {
+	lr.buf = lr.buf[:0]
+	for {
+		chunk, err := lr.r.ReadSlice('\n')
+		lr.buf = append(lr.buf, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil && (err != io.EOF || len(lr.buf) == 0) {
+			return nil, err
+		}
+		lr.line++
+		return bytes.TrimRight(lr.buf, "\r\n"), nil
+	}
+}
+
+// LineWriter writes newline delimited json, one value per line
+type LineWriter struct {
+	w    io.Writer
+	opts MarshalOptions
+	buf  bytes.Buffer
+}
+
+// NewLineWriter returns a writer of newline delimited json to w
+// opts are the same as for MarshalWith except indentation, every value takes exactly one line
+func NewLineWriter(w io.Writer, opts ...MarshalOptions) *LineWriter {
+	lw := &LineWriter{w: w}
+	if len(opts) > 0 {
+		lw.opts = opts[0]
+	}
+	lw.opts.Prefix, lw.opts.Indent = "", ""
+	return lw
+}
+
+// Write writes json of g followed by a newline
+func (lw *LineWriter) Write(g *GoJSON) error {
+	lw.buf.Reset()
+	g.MarshalWith(lw.opts, &lw.buf)
+	lw.buf.WriteByte('\n')
+	_, err := lw.w.Write(lw.buf.Bytes())
+	return err
+}
@@ -0,0 +1,235 @@
+package gojson
+
+import (
+	"strconv"
+)
+
+// MergePatch applies RFC 7386 JSON Merge Patch to target in place, objects are merged recursively,
+// null members of the patch delete members of target and any other value replaces target
+func MergePatch(target, patch *GoJSON) {
+	if patch.Type != JSONObject {
+		target.assign(patch.Clone())
+		return
+	}
+	if target.Type != JSONObject {
+		target.assign(NewObject())
+	}
+	for _, key := range patch.orderedKeys() {
+		value := patch.Map[key]
+		current, found := target.Map[key]
+		switch {
+		case value.Type == JSONNull:
+			if found {
+				target.Delete(key)
+			}
+		case found:
+			MergePatch(current, value)
+		default:
+			// nulls are removed from added objects as well
+			current = &GoJSON{Type: JSONNull}
+			MergePatch(current, value)
+			target.Set(key, current)
+		}
+	}
+}
+
+// ArrayStrategy tells DeepMerge how to combine two arrays
+type ArrayStrategy int
+
+// array merge strategies
+const (
+	ArrayReplace    ArrayStrategy = iota // overlay array replaces base array
+	ArrayAppend                          // overlay elements are appended to base elements
+	ArrayUnion                           // overlay elements not equal to any base element are appended
+	ArrayMergeByKey                      // object elements with equal ArrayKey member are merged, others are appended
+)
+
+// MergeOptions configures DeepMerge
+type MergeOptions struct {
+	Arrays   ArrayStrategy
+	ArrayKey string // member identifying object elements for ArrayMergeByKey
+	// Conflict resolves different values at the same place that aren't both objects or both arrays,
+	// it gets JSON Pointer of the value in the result and returns the value to use or an error that stops merging,
+	// returned nil keeps base value, without Conflict overlay value wins
+	Conflict func(pointer string, base, overlay *GoJSON) (*GoJSON, error)
+}
+
+// DeepMerge returns new document with overlay merged into base, objects are merged recursively,
+// arrays according to options, base and overlay are not modified
+func DeepMerge(base, overlay *GoJSON, opts ...MergeOptions) (*GoJSON, error) {
+	m := merger{}
+	if len(opts) > 0 {
+		m.MergeOptions = opts[0]
+	}
+	return m.merge(nil, base, overlay)
+}
+
+type merger struct {
+	MergeOptions
+}
+
+func (m *merger) merge(path []string, base, overlay *GoJSON) (*GoJSON, error) {
+	switch {
+	case base.Type == JSONObject && overlay.Type == JSONObject:
+		result := NewObject()
+		for _, key := range base.orderedKeys() {
+			value := base.Map[key]
+			if other, found := overlay.Map[key]; found {
+				var err error
+				if value, err = m.merge(appendToken(path, key), value, other); err != nil {
+					return nil, err
+				}
+			} else {
+				value = value.Clone()
+			}
+			result.Set(key, value)
+		}
+		for _, key := range overlay.orderedKeys() {
+			if _, found := base.Map[key]; !found {
+				result.Set(key, overlay.Map[key].Clone())
+			}
+		}
+		return result, nil
+	case base.Type == JSONArray && overlay.Type == JSONArray:
+		return m.mergeArrays(path, base, overlay)
+	case nodesEqual(base, overlay):
+		return overlay.Clone(), nil
+	case m.Conflict != nil:
+		value, err := m.Conflict(FormatPointer(path...), base, overlay)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			value = base
+		}
+		return value.Clone(), nil
+	}
+	return overlay.Clone(), nil
+}
+
+func (m *merger) mergeArrays(path []string, base, overlay *GoJSON) (*GoJSON, error) {
+	if m.Arrays == ArrayReplace {
+		return overlay.Clone(), nil
+	}
+	result := base.Clone()
+	for _, value := range overlay.Array {
+		switch m.Arrays {
+		case ArrayUnion:
+			if indexOfEqual(result.Array, value) >= 0 {
+				continue
+			}
+		case ArrayMergeByKey:
+			if index := m.indexOfKey(result.Array, value); index >= 0 {
+				merged, err := m.merge(appendToken(path, strconv.Itoa(index)), result.Array[index], value)
+				if err != nil {
+					return nil, err
+				}
+				result.Array[index] = merged
+				merged.parent = result
+				continue
+			}
+		}
+		result.Set(-1, value.Clone())
+	}
+	return result, nil
+}
+
+// indexOfKey returns index of object element with the same ArrayKey member as value
+func (m *merger) indexOfKey(array []*GoJSON, value *GoJSON) int {
+	if value.Type != JSONObject {
+		return -1
+	}
+	key, found := value.Map[m.ArrayKey]
+	if !found {
+		return -1
+	}
+	for i, element := range array {
+		if element.Type == JSONObject {
+			if other, found := element.Map[m.ArrayKey]; found && nodesEqual(key, other) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// indexOfEqual returns index of the first element equal to value
+func indexOfEqual(array []*GoJSON, value *GoJSON) int {
+	for i, element := range array {
+		if nodesEqual(element, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Conflict is a place changed differently by both sides of Merge3,
+// values are nodes of the merged documents, nil if the value is absent on that side
+type Conflict struct {
+	Pointer string
+	Base    *GoJSON
+	Ours    *GoJSON
+	Theirs  *GoJSON
+}
+
+// Merge3 combines changes made by ours and theirs to their common ancestor base into a new document,
+// objects are merged key by key at every depth, arrays index by index if none of them changed length,
+// conflicting changes are returned and the result keeps ours value for them
+func Merge3(base, ours, theirs *GoJSON) (*GoJSON, []Conflict) {
+	var conflicts []Conflict
+	return merge3(nil, base, ours, theirs, &conflicts), conflicts
+}
+
+// merge3 returns merged value or nil if it is absent
+func merge3(path []string, base, ours, theirs *GoJSON, conflicts *[]Conflict) *GoJSON {
+	switch {
+	case optionalEqual(ours, theirs), optionalEqual(base, theirs):
+		return optionalClone(ours)
+	case optionalEqual(base, ours):
+		return optionalClone(theirs)
+	case ours != nil && theirs != nil && ours.Type == JSONObject && theirs.Type == JSONObject &&
+		(base == nil || base.Type == JSONObject):
+		result := NewObject()
+		keys := ours.orderedKeys()
+		for _, key := range theirs.orderedKeys() {
+			if _, found := ours.Map[key]; !found {
+				keys = append(keys[:len(keys):len(keys)], key)
+			}
+		}
+		for _, key := range keys {
+			var baseValue *GoJSON
+			if base != nil {
+				baseValue = base.Map[key]
+			}
+			if value := merge3(appendToken(path, key), baseValue, ours.Map[key], theirs.Map[key], conflicts); value != nil {
+				result.Set(key, value)
+			}
+		}
+		return result
+	case ours != nil && theirs != nil && base != nil && ours.Type == JSONArray && theirs.Type == JSONArray &&
+		base.Type == JSONArray && len(ours.Array) == len(base.Array) && len(theirs.Array) == len(base.Array):
+		result := NewArray()
+		for i := range base.Array {
+			value := merge3(appendToken(path, strconv.Itoa(i)), base.Array[i], ours.Array[i], theirs.Array[i], conflicts)
+			result.Set(-1, value)
+		}
+		return result
+	}
+	*conflicts = append(*conflicts, Conflict{Pointer: FormatPointer(path...), Base: base, Ours: ours, Theirs: theirs})
+	return optionalClone(ours)
+}
+
+// optionalEqual compares values that may be absent
+func optionalEqual(a, b *GoJSON) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return nodesEqual(a, b)
+}
+
+func optionalClone(node *GoJSON) *GoJSON {
+	if node == nil {
+		return nil
+	}
+	return node.Clone()
+}
@@ -0,0 +1,140 @@
+package gojson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// examples from RFC 7386 appendix A
+	cases := [][3]string{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, c := range cases {
+		target, patch := Unmarshal([]byte(c[0])), Unmarshal([]byte(c[1]))
+		MergePatch(target, patch)
+		if out := string(target.Marshal()); out != c[2] {
+			t.Fatalf("%s + %s: expected %s, got %s", c[0], c[1], c[2], out)
+		}
+	}
+
+	json := Unmarshal([]byte(`{"a": {"b": 1, "c": 2}}`))
+	patch := Unmarshal([]byte(`{"a": {"d": [3]}}`))
+	MergePatch(json, patch)
+	patch.Get("a").Get("d").Set(-1, numberNode(4))
+	if out := string(json.Marshal()); out != `{"a":{"b":1,"c":2,"d":[3]}}` {
+		t.Fatal("patch value is shared with the target", out)
+	}
+	if p := json.Get("a").Get("d").Get(0).Pointer(); p != "/a/d/0" {
+		t.Fatal("wrong pointer", p)
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	base := `{"name": "app", "db": {"host": "localhost", "port": 5432}, "tags": ["a", "b"],
+		"users": [{"id": 1, "role": "user"}, {"id": 2, "role": "user"}]}`
+	overlay := `{"db": {"port": 6432, "ssl": true}, "tags": ["b", "c"],
+		"users": [{"id": 2, "role": "admin"}, {"id": 3}, {"role": "guest"}], "debug": true}`
+	cases := map[ArrayStrategy]string{
+		ArrayReplace: `{"name":"app","db":{"host":"localhost","port":6432,"ssl":true},"tags":["b","c"],` +
+			`"users":[{"id":2,"role":"admin"},{"id":3},{"role":"guest"}],"debug":true}`,
+		ArrayAppend: `{"name":"app","db":{"host":"localhost","port":6432,"ssl":true},"tags":["a","b","b","c"],` +
+			`"users":[{"id":1,"role":"user"},{"id":2,"role":"user"},{"id":2,"role":"admin"},{"id":3},{"role":"guest"}],"debug":true}`,
+		ArrayUnion: `{"name":"app","db":{"host":"localhost","port":6432,"ssl":true},"tags":["a","b","c"],` +
+			`"users":[{"id":1,"role":"user"},{"id":2,"role":"user"},{"id":2,"role":"admin"},{"id":3},{"role":"guest"}],"debug":true}`,
+		ArrayMergeByKey: `{"name":"app","db":{"host":"localhost","port":6432,"ssl":true},"tags":["a","b","b","c"],` +
+			`"users":[{"id":1,"role":"user"},{"id":2,"role":"admin"},{"id":3},{"role":"guest"}],"debug":true}`,
+	}
+	for strategy, expected := range cases {
+		a, b := Unmarshal([]byte(base)), Unmarshal([]byte(overlay))
+		result, err := DeepMerge(a, b, MergeOptions{Arrays: strategy, ArrayKey: "id"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out := string(result.Marshal()); out != expected {
+			t.Fatalf("strategy %d: expected %s, got %s", strategy, expected, out)
+		}
+		if string(a.Marshal()) != string(Unmarshal([]byte(base)).Marshal()) || string(b.Marshal()) != string(Unmarshal([]byte(overlay)).Marshal()) {
+			t.Fatal("input was modified")
+		}
+		if p := result.Get("users").Get(1).Get("id").Pointer(); p != "/users/1/id" {
+			t.Fatal("wrong pointer", p)
+		}
+	}
+
+	var conflicts []string
+	opts := MergeOptions{Arrays: ArrayMergeByKey, ArrayKey: "id", Conflict: func(pointer string, base, overlay *GoJSON) (*GoJSON, error) {
+		conflicts = append(conflicts, pointer)
+		if pointer == "/db/port" {
+			return nil, nil
+		}
+		return overlay, nil
+	}}
+	result, err := DeepMerge(Unmarshal([]byte(base)), Unmarshal([]byte(overlay)), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(conflicts, ",") != "/db/port,/users/1/role" || result.Get("db").Get("port").Marshal()[0] != '5' {
+		t.Fatal("unexpected conflicts", conflicts, string(result.Marshal()))
+	}
+
+	stop := errors.New("stop")
+	opts.Conflict = func(string, *GoJSON, *GoJSON) (*GoJSON, error) { return nil, stop }
+	if result, err = DeepMerge(Unmarshal([]byte(`{"a": 1}`)), Unmarshal([]byte(`{"a": "1"}`)), opts); err != stop || result != nil {
+		t.Fatal("expected error", result, err)
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	base := Unmarshal([]byte(`{"title": "a", "body": {"text": "x", "size": 1}, "tags": ["t1", "t2"], "owner": "ann", "old": 1}`))
+	ours := Unmarshal([]byte(`{"title": "b", "body": {"text": "y", "size": 1}, "tags": ["t1", "t3"], "owner": "bob", "new": {"x": 1}}`))
+	theirs := Unmarshal([]byte(`{"title": "a", "body": {"text": "x", "size": 2}, "tags": ["t1", "t2", "t4"], "old": 1, "new": {"x": 1, "y": 2}}`))
+	result, conflicts := Merge3(base, ours, theirs)
+	expected := `{"title":"b","body":{"text":"y","size":2},"tags":["t1","t3"],"owner":"bob","new":{"x":1,"y":2}}`
+	if out := string(result.Marshal()); out != expected {
+		t.Fatalf("expected %s, got %s", expected, out)
+	}
+	if len(conflicts) != 2 {
+		t.Fatal("unexpected conflicts", conflicts)
+	}
+	if c := conflicts[0]; c.Pointer != "/tags" || string(c.Base.Marshal()) != `["t1","t2"]` ||
+		string(c.Ours.Marshal()) != `["t1","t3"]` || string(c.Theirs.Marshal()) != `["t1","t2","t4"]` {
+		t.Fatal("unexpected conflict", c)
+	}
+	if c := conflicts[1]; c.Pointer != "/owner" || string(c.Base.Marshal()) != `"ann"` || string(c.Ours.Marshal()) != `"bob"` || c.Theirs != nil {
+		t.Fatal("unexpected conflict", c)
+	}
+
+	cases := [][4]string{
+		{`[1, 2, 3]`, `[9, 2, 3]`, `[1, 2, 8]`, `[9,2,8]`},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, `{"a": 1, "b": 2.0}`, `{"a":1,"b":2}`},
+		{`{"a": 1}`, `{"a": 1, "b": 2}`, `{"a": 1, "b": 3}`, `{"a":1,"b":2}`},
+		{`{"a": {"b": 1}}`, `{}`, `{"a": {"b": 1}}`, `{}`},
+		{`{"a": {"b": 1}}`, `{"a": {"b": 1}, "c": {"d": 1}}`, `{"c": {"e": 1}}`, `{"c":{"d":1,"e":1}}`},
+		{`1`, `"x"`, `[1]`, `"x"`},
+	}
+	for _, c := range cases {
+		result, conflicts := Merge3(Unmarshal([]byte(c[0])), Unmarshal([]byte(c[1])), Unmarshal([]byte(c[2])))
+		if out := string(result.Marshal()); out != c[3] {
+			t.Fatalf("%v: got %s, %v", c, out, conflicts)
+		}
+	}
+	if result.Get("body").Get("size").Pointer() != "/body/size" {
+		t.Fatal("wrong parent of result node")
+	}
+}
@@ -0,0 +1,130 @@
+package gojson
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// ErrOverflow is returned by numeric getters when the number doesn't fit into the requested type
+var ErrOverflow = errors.New("number overflows requested type")
+
+// ErrNotInteger is returned by integer getters when the number has a fractional part
+var ErrNotInteger = errors.New("number is not an integer")
+
+// maxExponent limits exponent of numbers converted to big.Int and big.Rat,
+// without it a short input like 1e999999999 would take minutes to expand
+const maxExponent = 10000
+
+// Number holds the original text of a json number, like json.Number
+type Number string
+
+// String returns the number text
+func (n Number) String() string {
+	return string(n)
+}
+
+// Int64 returns the number as int64, numbers like 1.0 and 1e3 are accepted as long as they are integers
+func (n Number) Int64() (int64, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return i, nil
+	}
+	b, err := n.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !b.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return b.Int64(), nil
+}
+
+// Uint64 returns the number as uint64, negative numbers overflow
+func (n Number) Uint64() (uint64, error) {
+	if i, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return i, nil
+	}
+	b, err := n.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !b.IsUint64() {
+		return 0, ErrOverflow
+	}
+	return b.Uint64(), nil
+}
+
+// Float64 returns the nearest float64, ErrOverflow is returned if the number is out of float64 range
+func (n Number) Float64() (float64, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return 0, ErrOverflow
+		}
+		return 0, fmt.Errorf("invalid number %q", string(n))
+	}
+	return f, nil
+}
+
+// BigInt returns the number as *big.Int, ErrNotInteger is returned if the number has a fractional part
+func (n Number) BigInt() (*big.Int, error) {
+	r, err := n.Rat()
+	if err != nil {
+		return nil, err
+	}
+	if !r.IsInt() {
+		return nil, ErrNotInteger
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
+
+// BigFloat returns the number as *big.Float with enough precision to hold all its digits
+func (n Number) BigFloat() (*big.Float, error) {
+	prec := uint(len(n)) * 4
+	if prec < 64 {
+		prec = 64
+	}
+	f, _, err := big.ParseFloat(string(n), 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", string(n))
+	}
+	return f, nil
+}
+
+// Rat returns exact value of the number as *big.Rat
+func (n Number) Rat() (*big.Rat, error) {
+	if exp := n.exponent(); exp > maxExponent || exp < -maxExponent {
+		return nil, ErrOverflow
+	}
+	r, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", string(n))
+	}
+	return r, nil
+}
+
+// exponent returns value of the exponent part, it saturates instead of overflowing
+func (n Number) exponent() int {
+	for i := 0; i < len(n); i++ {
+		if n[i] != 'e' && n[i] != 'E' {
+			continue
+		}
+		i++
+		sign := 1
+		if i < len(n) && (n[i] == '+' || n[i] == '-') {
+			if n[i] == '-' {
+				sign = -1
+			}
+			i++
+		}
+		exp := 0
+		for ; i < len(n) && isDigit(n[i]); i++ {
+			if exp <= maxExponent {
+				exp = exp*10 + int(n[i]-'0')
+			}
+		}
+		return sign * exp
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+package gojson
+
+import (
+	"testing"
+)
+
+func TestGoJSON_ValueInt64(t *testing.T) {
+	json := Unmarshal([]byte(`[9007199254740993, 18446744073709551615, 1e3, 1.5, -1, 123456789012345678901234567890.5, 1e400]`))
+
+	if i, err := json.Get(0).ValueInt64(); err != nil || i != 9007199254740993 {
+		t.Fatal("wrong int64", i, err)
+	}
+	if _, err := json.Get(1).ValueInt64(); err != ErrOverflow {
+		t.Fatal("expected overflow", err)
+	}
+	if u, err := json.Get(1).ValueUint64(); err != nil || u != 18446744073709551615 {
+		t.Fatal("wrong uint64", u, err)
+	}
+	if i, err := json.Get(2).ValueInt(); err != nil || i != 1000 {
+		t.Fatal("wrong int", i, err)
+	}
+	if _, err := json.Get(3).ValueInt(); err != ErrNotInteger {
+		t.Fatal("expected not integer", err)
+	}
+	if i, _ := json.Get(3).ValueInt(7); i != 7 {
+		t.Fatal("expected default", i)
+	}
+	if _, err := json.Get(4).ValueUint64(); err != ErrOverflow {
+		t.Fatal("expected overflow", err)
+	}
+	if _, err := json.Get(6).ValueFloat(); err != ErrOverflow {
+		t.Fatal("expected overflow", err)
+	}
+	r, err := json.Get(5).ValueRat()
+	if err != nil || r.String() != "246913578024691357802469135781/2" {
+		t.Fatal("wrong rat", r, err)
+	}
+	f, err := json.Get(5).ValueBigFloat()
+	if err != nil || f.Text('f', 1) != "123456789012345678901234567890.5" {
+		t.Fatal("wrong big float", f, err)
+	}
+	b, err := json.Get(6).ValueBigInt()
+	if err != nil || len(b.String()) != 401 {
+		t.Fatal("wrong big int", err)
+	}
+	if _, err := Number("1e99999999").BigInt(); err != ErrOverflow {
+		t.Fatal("expected overflow", err)
+	}
+}
@@ -2,10 +2,17 @@ package gojson
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 	"unsafe"
-	"fmt"
-	"strconv"
 )
 
 const (
@@ -17,15 +24,123 @@ const (
 	escape      byte = '\\'
 )
 
+// maxDepth is the maximum nesting of arrays and objects, deeper input would overflow the stack
+const maxDepth = 10000
 
-// Unarshal parses input bytes and returns new json
-func Unmarshal(value []byte) *GoJSON {
-	json := &GoJSON{}
-	var node *GoJSON
-	parseValue(json, node, skip(value))
+// excerptRadius is the number of bytes taken on each side of the error offset for SyntaxError.Excerpt
+const excerptRadius = 16
+
+// SyntaxError describes malformed json input
+type SyntaxError struct {
+	Offset   int    // byte offset of the error in the input
+	Line     int    // line of the error, starting from 1
+	Column   int    // column of the error in bytes, starting from 1
+	Expected string // what parser expected to find at Offset
+	Excerpt  string // part of the input around Offset
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Excerpt == "" {
+		return fmt.Sprintf("invalid json at line %d, column %d (offset %d): expected %s", e.Line, e.Column, e.Offset, e.Expected)
+	}
+	return fmt.Sprintf("invalid json at line %d, column %d (offset %d): expected %s near %q", e.Line, e.Column, e.Offset, e.Expected, e.Excerpt)
+}
+
+func newSyntaxError(data []byte, offset int, expected string) *SyntaxError {
+	e := &SyntaxError{Offset: offset, Line: 1, Column: 1, Expected: expected}
+	lineStart := 0
+	for i := 0; i < offset; i++ {
+		if data[i] == '\n' {
+			e.Line++
+			lineStart = i + 1
+		}
+	}
+	e.Column = offset - lineStart + 1
+	from, to := offset-excerptRadius, offset+excerptRadius
+	if from < 0 {
+		from = 0
+	}
+	if to > len(data) {
+		to = len(data)
+	}
+	e.Excerpt = string(data[from:to])
+	return e
+}
+
+// ParseOptions configures Parse and Unmarshal
+type ParseOptions struct {
+	// Strict rejects everything RFC 8259 forbids: data after the root value, leading zeros,
+	// incomplete numbers, unknown escapes, raw control characters and invalid UTF-8 in strings
+	Strict bool
+}
+
+// parser holds the whole input so errors can be reported with their position
+type parser struct {
+	data  []byte
+	opts  ParseOptions
+	depth int // number of arrays and objects being parsed
+}
+
+func newParser(value []byte, opts []ParseOptions) *parser {
+	p := &parser{data: value}
+	if len(opts) > 0 {
+		p.opts = opts[0]
+	}
+	return p
+}
+
+// fail aborts parsing, value is the unparsed rest of the input
+func (p *parser) fail(value []byte, expected string) {
+	panic(newSyntaxError(p.data, len(p.data)-len(value), expected))
+}
+
+// catch converts *SyntaxError panic of the parser to err
+func catch(err *error) {
+	if r := recover(); r != nil {
+		syntaxErr, ok := r.(*SyntaxError)
+		if !ok {
+			panic(r)
+		}
+		*err = syntaxErr
+	}
+}
+
+// Parse parses input bytes and returns new json
+// malformed input is reported as *SyntaxError, Parse never panics
+func Parse(value []byte, opts ...ParseOptions) (json *GoJSON, err error) {
+	defer catch(&err)
+	p := newParser(value, opts)
+	return p.parse(p.opts.Strict), nil
+}
+
+// Unmarshal parses input bytes and returns new json
+// malformed input returns empty node of JSONInvalid type, use Parse to get the error
+func Unmarshal(value []byte, opts ...ParseOptions) *GoJSON {
+	json, err := Parse(value, opts...)
+	if err != nil {
+		return &GoJSON{}
+	}
 	return json
 }
 
+// parseWhole is like Parse but rejects data after the root value in lenient mode too
+func parseWhole(value []byte, opts ParseOptions) (json *GoJSON, err error) {
+	defer catch(&err)
+	p := parser{data: value, opts: opts}
+	return p.parse(true), nil
+}
+
+// parse parses the root value, data after it is rejected if whole is set
+func (p *parser) parse(whole bool) *GoJSON {
+	json := &GoJSON{}
+	rest := p.parseValue(json, p.skip(p.data))
+	if whole {
+		if rest = p.skip(rest); len(rest) > 0 {
+			p.fail(rest, "end of input")
+		}
+	}
+	return json
+}
 
 func skip(value []byte) []byte {
 	i := 0
@@ -35,306 +150,599 @@ func skip(value []byte) []byte {
 	return value[i:]
 }
 
-func parseKey(json *GoJSON, node *GoJSON, value []byte) []byte {
-	if value[0] != startString {
-		syntaxError()
+// skip is strict aware version of skip, it leaves anything except RFC 8259 whitespace in place
+func (p *parser) skip(value []byte) []byte {
+	if !p.opts.Strict {
+		return skip(value)
 	}
-	i := 1
-	for i < len(value) {
-		if value[i] == startString {
-			if value[i-1] == escape {
-				i++
-				continue
-			}
-			break
-		}
+	i := 0
+	for i < len(value) && (value[i] == ' ' || value[i] == '\t' || value[i] == '\n' || value[i] == '\r') {
 		i++
 	}
-	if json.Map == nil {
-		json.Map = make(map[string]*GoJSON)
+	return value[i:]
+}
+
+// scanString returns index of the closing quote of the string at the beginning of value
+// and whether the string contains escape sequences
+func (p *parser) scanString(value []byte) (int, bool) {
+	escaped := false
+	for i := 1; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == startString:
+			return i, escaped
+		case c == escape:
+			escaped = true
+			i++
+		case !p.opts.Strict:
+			continue
+		case c < 0x20:
+			p.fail(value[i:], "escaped control character")
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(value[i:])
+			if r == utf8.RuneError && size == 1 {
+				p.fail(value[i:], "valid UTF-8")
+			}
+			i += size - 1
+		}
 	}
-	json.Map[bytesToStr(value[1:i])] = node
-	return value[i+1:]
+	p.fail(value[len(value):], "end of string")
+	return 0, false
 }
 
-func parseValue(json *GoJSON, node *GoJSON, value []byte) []byte {
-	if len(value) == 0 {
-		return value
+// readString returns decoded content of the string at the beginning of value and the rest of the input
+// strings without escape sequences are not copied
+func (p *parser) readString(value []byte) ([]byte, []byte) {
+	end, escaped := p.scanString(value)
+	if !escaped {
+		return value[1:end], value[end+1:]
 	}
+	return p.unescape(value, end), value[end+1:]
+}
 
-	switch value[0] {
-	case 'n': // n
-		if len(value) >= 4 && value[1] == 'u' && value[2] == 'l' && value[3] == 'l' {
-			node.Type = JSONNull
-			node.Bytes = value[:4]
-			return value[4:]
+// unescape decodes escape sequences of the string value[:end+1] including quotes
+func (p *parser) unescape(value []byte, end int) []byte {
+	b := make([]byte, 0, end)
+	s := bytesToStr(value[:end])
+	var runeBuf [utf8.UTFMax]byte
+	for i := 1; i < end; {
+		c := value[i]
+		if c != escape {
+			b = append(b, c)
+			i++
+			continue
 		}
-	case 'f': // f
-		if len(value) >= 5 && value[1] == 'a' && value[2] == 'l' && value[3] == 's' && value[4] == 'e' { // e
-			node.Type = JSONBool
-			node.Bytes = value[:5]
-			return value[5:]
+		r, next, err := decodeEscape(s, i, startString)
+		switch err {
+		case nil, errLoneSurrogate, errSurrogatePair: // lone surrogates are replaced
+		case errHex4:
+			p.fail(value[i:], "\\uXXXX escape")
+		default:
+			p.fail(value[i:], "escape sequence")
 		}
-	case 't': // t
-		if len(value) >= 4 && value[1] == 'r' && value[2] == 'u' && value[3] == 'e' {
-			node.Type = JSONBool
-			node.Bytes = value[:4]
-			return value[4:]
+		n := utf8.EncodeRune(runeBuf[:], r)
+		b = append(b, runeBuf[:n]...)
+		i = next
+	}
+	return b
+}
+
+var (
+	errEscape        = errors.New("invalid escape sequence")
+	errHex4          = errors.New("expected 4 hex digits")
+	errLoneSurrogate = errors.New("lone surrogate")
+	errSurrogatePair = errors.New("invalid surrogate pair")
+)
+
+// decodeEscape decodes escape sequence with backslash at s[i], quote is the quote character allowed to be escaped,
+// it returns the rune and offset after the sequence or offset of the problem with error,
+// surrogate without a pair is returned as unicode.ReplacementChar with offset after its escape
+func decodeEscape(s string, i int, quote byte) (rune, int, error) {
+	if i++; i >= len(s) {
+		return 0, i, errEscape
+	}
+	switch c := s[i]; c {
+	case 'b':
+		return '\b', i + 1, nil
+	case 'f':
+		return '\f', i + 1, nil
+	case 'n':
+		return '\n', i + 1, nil
+	case 'r':
+		return '\r', i + 1, nil
+	case 't':
+		return '\t', i + 1, nil
+	case '/', '\\', quote:
+		return rune(c), i + 1, nil
+	case 'u':
+	default:
+		return 0, i, errEscape
+	}
+	r, ok := hex4(s[i+1:])
+	if !ok {
+		return 0, i + 1, errHex4
+	}
+	if i += 5; !utf16.IsSurrogate(r) {
+		return r, i, nil
+	}
+	// surrogate pair is two escapes in a row
+	if !strings.HasPrefix(s[i:], `\u`) {
+		return unicode.ReplacementChar, i, errLoneSurrogate
+	}
+	if r2, ok := hex4(s[i+2:]); ok {
+		if d := utf16.DecodeRune(r, r2); d != unicode.ReplacementChar {
+			return d, i + 6, nil
 		}
+	}
+	return unicode.ReplacementChar, i, errSurrogatePair
+}
+
+// hex4 decodes four hex digits at the beginning of s
+func hex4(s string) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range []byte(s[:4]) {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (p *parser) parseKey(value []byte) (string, []byte) {
+	if len(value) == 0 || value[0] != startString {
+		p.fail(value, "string key")
+	}
+	key, rest := p.readString(value)
+	return bytesToStr(key), rest
+}
+
+func (p *parser) parseValue(node *GoJSON, value []byte) []byte {
+	if len(value) == 0 {
+		p.fail(value, "value")
+	}
+
+	switch value[0] {
+	case 'n':
+		return p.parseLiteral(node, value, "null", JSONNull)
+	case 'f':
+		return p.parseLiteral(node, value, "false", JSONBool)
+	case 't':
+		return p.parseLiteral(node, value, "true", JSONBool)
 	case startString: // "
-		return parseString(node, value)
+		return p.parseString(node, value)
 	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '-': // - , 0-9
-		return parseNumber(node, value)
+		return p.parseNumber(node, value)
 	case startArray:
-		return parseArray(json, node, value)
+		return p.parseArray(node, value)
 	case startObject:
-		return parseObject(json, node, value)
+		return p.parseObject(node, value)
 	}
+	p.fail(value, "value")
 	return value
 }
 
-func parseString(node *GoJSON, value []byte) []byte {
-	if value[0] != startString {
-		syntaxError()
-	}
-	i := 1
-	for i < len(value) {
-		if value[i] == startString {
-			if value[i-1] == escape {
-				i++
-				continue
-			}
-			break
-		}
-		i++
+func (p *parser) parseLiteral(node *GoJSON, value []byte, literal string, Type JSONType) []byte {
+	if len(value) < len(literal) || bytesToStr(value[:len(literal)]) != literal {
+		p.fail(value, literal)
 	}
+	node.Type = Type
+	node.Bytes = value[:len(literal)]
+	return value[len(literal):]
+}
 
-	node.Bytes = value[1:i]
+func (p *parser) parseString(node *GoJSON, value []byte) []byte {
 	node.Type = JSONString
-	return value[i+1:]
+	node.Bytes, value = p.readString(value)
+	return value
 }
 
-func parseNumber(node *GoJSON, value []byte) []byte {
+// parseNumber parses number following RFC 8259 grammar: [ minus ] int [ frac ] [ exp ]
+// original text of the number is kept, numbers with fraction or exponent are JSONFloat
+// lenient mode tolerates leading zeros and missing digits
+func (p *parser) parseNumber(node *GoJSON, value []byte) []byte {
 	i := 0
-	nodeType := JSONInt
-	hasExponent := false
-	if value[i] == 45 {
+	if value[i] == '-' {
 		i++
-	} /* - */
-	loop:
-	for i < len(value) {
-		switch value[i] {
-		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-			i++
-		case 'E':
-			hasExponent = true
-			i++
-		case '.':
-			nodeType = JSONFloat
+	}
+	if p.opts.Strict && i+1 < len(value) && value[i] == '0' && isDigit(value[i+1]) {
+		p.fail(value[i:], "number without leading zeros")
+	}
+	i = p.digits(value, i)
+	node.Type = JSONInt
+	if i < len(value) && value[i] == '.' {
+		node.Type = JSONFloat
+		i = p.digits(value, i+1)
+	}
+	if i < len(value) && (value[i] == 'e' || value[i] == 'E') {
+		node.Type = JSONFloat
+		i++
+		if i < len(value) && (value[i] == '+' || value[i] == '-') {
 			i++
-		default:
-			break loop
 		}
+		i = p.digits(value, i)
 	}
-	node.Type = nodeType
 	node.Bytes = value[:i]
-	if hasExponent {
-		result, err := strconv.ParseFloat(bytesToStr(node.Bytes), 64)
-		if err == nil {
-			node.Bytes = []byte(fmt.Sprintf("%f", result))
-		}
-	}
 	return value[i:]
 }
 
-func parseArray(json *GoJSON, node *GoJSON, value []byte) []byte {
-	// Check for empty array
-	var parent *GoJSON
-	if json.Type != JSONInvalid {
-		// is child
-		if node != nil {
-			node.Type = JSONArray
-		} else {
-			node = NewArray()
-		}
-		parent = node
-	} else {
-		parent = json
-	}
-
-	parent.Type = JSONArray
-	value = skip(value[1:])
-	if len(value) > 0 && value[0] == stopArray {
-		return value[1:]
+// digits skips digits starting from value[i], strict mode requires at least one
+func (p *parser) digits(value []byte, i int) int {
+	start := i
+	for i < len(value) && isDigit(value[i]) {
+		i++
 	}
-
-	newNode := &GoJSON{}
-	value = skip(parseValue(parent, newNode, skip(value)))
-	if len(value) == 0 {
-		return value
+	if i == start && p.opts.Strict {
+		p.fail(value[i:], "digit")
 	}
+	return i
+}
 
-	if parent.Array == nil {
-		parent.Array = make([]*GoJSON, 1)
+// enter counts nested array or object starting at value, leave must be called when it ends
+func (p *parser) enter(value []byte) {
+	if p.depth++; p.depth > maxDepth {
+		p.fail(value, "at most 10000 nested arrays and objects")
 	}
-	parent.Array[0] = newNode
+}
 
-	for value[0] == 44 { // ,
-		newNode = &GoJSON{}
-		value = skip(parseValue(parent, newNode, skip(value[1:])))
-		if len(value) == 0 {
-			return value
-		}
-		parent.Array = append(parent.Array, newNode)
-	}
+func (p *parser) leave() {
+	p.depth--
+}
 
+func (p *parser) parseArray(node *GoJSON, value []byte) []byte {
+	node.Type = JSONArray
+	p.enter(value)
+	value = p.skip(value[1:])
+	// Check for empty array
 	if len(value) > 0 && value[0] == stopArray {
+		p.leave()
 		return value[1:]
 	}
-	return value
-}
 
-func parseObject(json *GoJSON, node *GoJSON, value []byte) []byte {
-	// check for empty object
-	var parent *GoJSON
-
-	if json.Type != JSONInvalid {
-		// is child
-		if node != nil {
-			node.Type = JSONObject
-		} else {
-			node = NewObject()
+	for {
+		newNode := &GoJSON{parent: node}
+		value = p.skip(p.parseValue(newNode, value))
+		node.Array = append(node.Array, newNode)
+		if len(value) == 0 {
+			p.fail(value, "',' or ']'")
+		}
+		switch value[0] {
+		case ',':
+			value = p.skip(value[1:])
+		case stopArray:
+			p.leave()
+			return value[1:]
+		default:
+			p.fail(value, "',' or ']'")
 		}
-		parent = node
-	} else {
-		parent = json
 	}
+}
 
-	parent.Type = JSONObject
-	value = skip(value[1:])
+func (p *parser) parseObject(node *GoJSON, value []byte) []byte {
+	node.Type = JSONObject
+	p.enter(value)
+	value = p.skip(value[1:])
+	// check for empty object
 	if len(value) > 0 && value[0] == stopObject {
+		p.leave()
 		return value[1:]
 	}
 
-	newNode := &GoJSON{}
-	value = skip(parseKey(parent, newNode, skip(value)))
-	if len(value) == 0 || value[0] != ':' {
-		syntaxError()
-	}
-	value = skip(parseValue(parent, newNode, skip(value[1:])))
-	if len(value) == 0 {
-		return value
-	}
-
-	for value[0] == ',' { // ,
-		newNode = &GoJSON{}
-		value = skip(parseKey(parent, newNode, skip(value[1:])))
+	for {
+		key, rest := p.parseKey(value)
+		value = p.skip(rest)
 		if len(value) == 0 || value[0] != ':' {
-			syntaxError()
+			p.fail(value, "':'")
+		}
+		newNode := &GoJSON{parent: node}
+		value = p.skip(p.parseValue(newNode, p.skip(value[1:])))
+		if node.Map == nil {
+			node.Map = make(map[string]*GoJSON)
 		}
-		value = skip(parseValue(parent, newNode, skip(value[1:])))
+		if _, found := node.Map[key]; !found {
+			node.keys = append(node.keys, key)
+		}
+		node.Map[key] = newNode
 		if len(value) == 0 {
-			return value
+			p.fail(value, "',' or '}'")
+		}
+		switch value[0] {
+		case ',':
+			value = p.skip(value[1:])
+		case stopObject:
+			p.leave()
+			return value[1:]
+		default:
+			p.fail(value, "',' or '}'")
 		}
 	}
+}
 
-	if len(value) > 0 && value[0] == stopObject {
-		return value[1:]
-	}
-	return value
+// MarshalOptions configures MarshalWith
+type MarshalOptions struct {
+	// SortKeys writes object keys in sorted order instead of insertion order
+	SortKeys bool
+	// Prefix starts every line of the output except the first one
+	Prefix string
+	// Indent is written once per nesting level, output is indented when Prefix or Indent is set
+	Indent string
+	// ColonSpace writes a space after the colon between key and value
+	ColonSpace bool
+	// EscapeHTML escapes <, > and & so output can be embedded into html
+	EscapeHTML bool
+	// ASCIIOnly escapes every non-ASCII character as \uXXXX
+	ASCIIOnly bool
+	// InlineArrayLimit keeps indented arrays of scalars on one line
+	// when the line would be shorter than this number of bytes, 0 disables it
+	InlineArrayLimit int
+}
+
+// writer is implemented by *bytes.Buffer and *bufio.Writer
+type writer interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// encoder writes json to the buffer according to MarshalOptions
+type encoder struct {
+	opts    MarshalOptions
+	bf      writer
+	depth   int
+	scratch *bytes.Buffer // used to measure inline arrays
 }
 
 // Marshal transforms goJSON to []byte
 func (g *GoJSON) Marshal(buf ...*bytes.Buffer) []byte {
+	return g.MarshalWith(MarshalOptions{}, buf...)
+}
+
+// MarshalWith transforms goJSON to []byte according to opts
+func (g *GoJSON) MarshalWith(opts MarshalOptions, buf ...*bytes.Buffer) []byte {
 	var bf *bytes.Buffer
 	if len(buf) > 0 {
 		bf = buf[0]
 	} else {
 		bf = &bytes.Buffer{}
 	}
+	e := encoder{opts: opts, bf: bf}
+	e.encode(g)
+	return bf.Bytes()
+}
+
+// MarshalIndent is like Marshal but writes every value on its own line,
+// starting with prefix and indented with indent once per nesting level
+func (g *GoJSON) MarshalIndent(prefix, indent string, buf ...*bytes.Buffer) []byte {
+	return g.MarshalWith(MarshalOptions{Prefix: prefix, Indent: indent, ColonSpace: true}, buf...)
+}
+
+// encode writes root node
+func (e *encoder) encode(g *GoJSON) {
+	switch g.Type {
+	case JSONObject, JSONArray, JSONInvalid:
+		e.marshal(g)
+	default:
+		e.writeValue(g)
+	}
+}
+
+func (e *encoder) indented() bool {
+	return e.opts.Prefix != "" || e.opts.Indent != ""
+}
+
+// newline starts a new line of indented output at current depth
+func (e *encoder) newline() {
+	if !e.indented() {
+		return
+	}
+	e.bf.WriteByte('\n')
+	e.bf.WriteString(e.opts.Prefix)
+	for i := 0; i < e.depth; i++ {
+		e.bf.WriteString(e.opts.Indent)
+	}
+}
+
+func (e *encoder) marshal(g *GoJSON) {
 	if g.Type == JSONObject {
-		bf.WriteByte(startObject)
-		idx := 0
-		for key, value := range g.Map {
-			if idx > 0 {
-				bf.WriteByte(44)
-			} else {
-				idx++
-			}
-			bf.WriteByte(startString)
-			bf.WriteString(key)
-			bf.WriteByte(startString)
-			bf.WriteByte(58)
-			writeValue(value, bf)
-		}
-		bf.WriteByte(stopObject)
+		e.writeObject(g)
 	} else {
-		bf.WriteByte(startArray)
-		for idx, value := range g.Array {
-			if idx > 0 {
-				bf.WriteByte(44)
-			}
-			writeValue(value, bf)
+		e.writeArray(g)
+	}
+}
+
+func (e *encoder) writeObject(g *GoJSON) {
+	bf := e.bf
+	keys := g.orderedKeys()
+	if e.opts.SortKeys {
+		keys = append([]string(nil), keys...)
+		sort.Strings(keys)
+	}
+	bf.WriteByte(startObject)
+	if len(keys) == 0 {
+		bf.WriteByte(stopObject)
+		return
+	}
+	e.depth++
+	for idx, key := range keys {
+		if idx > 0 {
+			bf.WriteByte(44)
+		}
+		e.newline()
+		e.writeString(key)
+		bf.WriteByte(58)
+		if e.opts.ColonSpace {
+			bf.WriteByte(' ')
 		}
+		e.writeValue(g.Map[key])
+	}
+	e.depth--
+	e.newline()
+	bf.WriteByte(stopObject)
+}
+
+func (e *encoder) writeArray(g *GoJSON) {
+	bf := e.bf
+	if len(g.Array) == 0 {
+		bf.WriteByte(startArray)
 		bf.WriteByte(stopArray)
+		return
 	}
-	return bf.Bytes()
+	if e.indented() && e.opts.InlineArrayLimit > 0 && e.writeInline(g) {
+		return
+	}
+	bf.WriteByte(startArray)
+	e.depth++
+	for idx, value := range g.Array {
+		if idx > 0 {
+			bf.WriteByte(44)
+		}
+		e.newline()
+		e.writeValue(value)
+	}
+	e.depth--
+	e.newline()
+	bf.WriteByte(stopArray)
 }
 
-func writeValue(value *GoJSON, bf *bytes.Buffer) {
+// writeInline writes array of scalars on one line if it fits into InlineArrayLimit
+func (e *encoder) writeInline(g *GoJSON) bool {
+	for _, value := range g.Array {
+		if value.Type == JSONArray || value.Type == JSONObject {
+			return false
+		}
+	}
+	if e.scratch == nil {
+		e.scratch = &bytes.Buffer{}
+	}
+	e.scratch.Reset()
+	inline := encoder{opts: e.opts, bf: e.scratch}
+	e.scratch.WriteByte(startArray)
+	for idx, value := range g.Array {
+		if idx > 0 {
+			e.scratch.WriteString(", ")
+		}
+		inline.writeValue(value)
+		if e.scratch.Len() >= e.opts.InlineArrayLimit {
+			return false
+		}
+	}
+	e.scratch.WriteByte(stopArray)
+	if e.scratch.Len() >= e.opts.InlineArrayLimit {
+		return false
+	}
+	e.bf.Write(e.scratch.Bytes())
+	return true
+}
+
+func (e *encoder) writeValue(value *GoJSON) {
 	switch value.Type {
 	case JSONString:
-		bf.WriteByte(startString)
-		var p int
-		for i := 0; i < len(value.Bytes); i++ {
-			c := value.Bytes[i]
-			var e byte
+		e.writeString(bytesToStr(value.Bytes))
+	case JSONArray, JSONObject:
+		e.marshal(value)
+	default:
+		e.bf.Write(value.Bytes)
+	}
+}
+
+const hexDigits = "0123456789abcdef"
+
+// writeString writes quoted and escaped string, control characters, U+2028, U+2029 and
+// invalid UTF-8 are always escaped, html characters and non-ASCII runes according to options
+func (e *encoder) writeString(s string) {
+	bf := e.bf
+	bf.WriteByte(startString)
+	p := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != 0x7f &&
+				(!e.opts.EscapeHTML || c != '<' && c != '>' && c != '&') {
+				// no escaping is required
+				i++
+				continue
+			}
+			bf.WriteString(s[p:i])
 			switch c {
+			case '"', '\\':
+				bf.WriteByte(escape)
+				bf.WriteByte(c)
 			case '\t':
-				e = 't'
+				bf.WriteString(`\t`)
 			case '\r':
-				e = 'r'
+				bf.WriteString(`\r`)
 			case '\n':
-				e = 'n'
-			case '\\':
-				e = '\\'
-			case '"':
-				e = '"'
-			//case '<', '>':
-			//	if !w.EscapeLtGt {
-			//		continue
-			//	}
+				bf.WriteString(`\n`)
+			case '\b':
+				bf.WriteString(`\b`)
+			case '\f':
+				bf.WriteString(`\f`)
 			default:
-				if c >= 0x20 {
-					// no escaping is required
-					continue
-				}
+				e.writeRuneEscape(rune(c))
 			}
-			if e != 0 {
-				bf.Write(value.Bytes[p:i])
-				bf.WriteByte(escape)
-				bf.WriteByte(e)
+			i++
+			p = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 || r == '\u2028' || r == '\u2029' || e.opts.ASCIIOnly {
+			bf.WriteString(s[p:i])
+			if r1, r2 := utf16.EncodeRune(r); r1 != unicode.ReplacementChar {
+				e.writeRuneEscape(r1)
+				e.writeRuneEscape(r2)
 			} else {
-				bf.Write(value.Bytes[p:i])
+				e.writeRuneEscape(r)
 			}
-			p = i + 1
+			p = i + size
 		}
-		bf.Write(value.Bytes[p:])
-		bf.WriteByte(startString)
-	case JSONArray, JSONObject:
-		value.Marshal(bf)
-	default:
-		bf.Write(value.Bytes)
+		i += size
 	}
+	bf.WriteString(s[p:])
+	bf.WriteByte(startString)
 }
 
+// WriteString writes s to buf as quoted json string escaped the same way Marshal escapes strings,
+// it is used by generated MarshalGoJSON methods
+func WriteString(buf *bytes.Buffer, s string) {
+	e := encoder{bf: buf}
+	e.writeString(s)
+}
+
+// AppendFloat appends json number of f to dst formatted like encoding/json does it:
+// exponent is used only for very small and very large numbers, NaN and infinities are written as null,
+// bits is 32 for float32 and 64 for float64
+func AppendFloat(dst []byte, f float64, bits int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(dst, "null"...)
+	}
+	return appendFloat(dst, f, bits)
+}
+
+// writeRuneEscape writes \uXXXX escape of BMP rune r
+func (e *encoder) writeRuneEscape(r rune) {
+	e.bf.WriteString(`\u`)
+	e.bf.WriteByte(hexDigits[r>>12&0xf])
+	e.bf.WriteByte(hexDigits[r>>8&0xf])
+	e.bf.WriteByte(hexDigits[r>>4&0xf])
+	e.bf.WriteByte(hexDigits[r&0xf])
+}
 
 func bytesToStr(data []byte) string {
 	h := (*reflect.SliceHeader)(unsafe.Pointer(&data))
 	sHdr := reflect.StringHeader{h.Data, h.Len}
 	return *(*string)(unsafe.Pointer(&sHdr))
 }
-
-func syntaxError() {
-	panic("Invalid json")
-}
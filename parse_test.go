@@ -1,8 +1,10 @@
 package gojson
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
+	"unicode"
 )
 
 var js *GoJSON
@@ -151,6 +153,276 @@ func TestGoJSON_ToMap(t *testing.T) {
 	fmt.Println(m)
 }
 
+func TestParse_SyntaxError(t *testing.T) {
+	cases := []struct {
+		input    string
+		offset   int
+		line     int
+		column   int
+		expected string
+	}{
+		{``, 0, 1, 1, "value"},
+		{`{"a": 1,`, 8, 1, 9, "string key"},
+		{"{\n  \"a\" 1}", 8, 2, 7, "':'"},
+		{`[1, 2`, 5, 1, 6, "',' or ']'"},
+		{`{"a": "b`, 8, 1, 9, "end of string"},
+		{`[tru]`, 1, 1, 2, "true"},
+		{"[1,\n\t]", 5, 2, 2, "value"},
+		{`{"a": 1 "b": 2}`, 8, 1, 9, "',' or '}'"},
+	}
+	for _, c := range cases {
+		json, err := Parse([]byte(c.input))
+		if json != nil {
+			t.Fatalf("%q: expected nil json", c.input)
+		}
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Fatalf("%q: expected *SyntaxError, got %v", c.input, err)
+		}
+		if syntaxErr.Offset != c.offset || syntaxErr.Line != c.line || syntaxErr.Column != c.column || syntaxErr.Expected != c.expected {
+			t.Fatalf("%q: unexpected error %+v", c.input, syntaxErr)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	json, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val, _ := json.Get("person").Get("github").Get("handle").ValueString()
+	if val != "buger" {
+		t.Fatal("wrong value", val)
+	}
+}
+
+func TestParse_Strict(t *testing.T) {
+	invalid := []string{
+		`{"a": 1} x`,
+		`[01]`,
+		`-`,
+		`[1.]`,
+		`[.5]`,
+		`1e`,
+		"\"a\tb\"",
+		"\"\xff\"",
+		`"\q"`,
+		`"\u12"`,
+		"\f[]",
+	}
+	for _, input := range invalid {
+		if _, err := Parse([]byte(input), ParseOptions{Strict: true}); err == nil {
+			t.Fatalf("%q: expected error in strict mode", input)
+		}
+	}
+	valid := []string{
+		`{"a": [0, -0.5, 1e10, 2E-3, 10.25e+2]}`,
+		`"\u00e9\n\/"`,
+		"\"\u00e9 €\"",
+		" null\r\n",
+	}
+	for _, input := range valid {
+		if _, err := Parse([]byte(input), ParseOptions{Strict: true}); err != nil {
+			t.Fatalf("%q: %v", input, err)
+		}
+	}
+	if _, err := Parse([]byte(`[01] x`)); err != nil {
+		t.Fatal("lenient mode should accept", err)
+	}
+}
+
+func TestParse_Unescape(t *testing.T) {
+	json := Unmarshal([]byte(`{"k\"e\u0079\n": "a\\b\"c\/\b\f\n\r\t\u00e9\ud83d\ude00\ud800x"}`))
+	val, err := json.Get("k\"ey\n").ValueString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != "a\\b\"c/\b\f\n\r\té😀\uFFFDx" {
+		t.Fatalf("wrong value %q", val)
+	}
+	if _, err := Parse([]byte(`"\x"`)); err == nil {
+		t.Fatal("expected error for unknown escape")
+	}
+	if _, err := Parse([]byte(`"\u12"`)); err == nil {
+		t.Fatal("expected error for short \\u escape")
+	}
+	// strings without escapes are not copied
+	input := []byte(`["plain"]`)
+	json = Unmarshal(input)
+	if &json.Get(0).Bytes[0] != &input[2] {
+		t.Fatal("plain string was copied")
+	}
+}
+
+func TestDecodeEscape(t *testing.T) {
+	type decoded struct {
+		r    rune
+		next int
+		err  error
+	}
+	for input, expected := range map[string]decoded{
+		`\n`:           {'\n', 2, nil},
+		`\'`:           {'\'', 2, nil},
+		`\"`:           {0, 1, errEscape},
+		`\`:            {0, 1, errEscape},
+		`\u00e9x`:      {'é', 6, nil},
+		`\u00g9`:       {0, 2, errHex4},
+		`\ud83d\ude00`: {'😀', 12, nil},
+		`\ud83dx`:      {unicode.ReplacementChar, 6, errLoneSurrogate},
+		`\ud83d\u0041`: {unicode.ReplacementChar, 6, errSurrogatePair},
+		`\ude00\ud83d`: {unicode.ReplacementChar, 6, errSurrogatePair},
+	} {
+		r, next, err := decodeEscape(input, 0, '\'')
+		if (decoded{r, next, err}) != expected {
+			t.Fatalf("%s: expected %v, got %v", input, expected, decoded{r, next, err})
+		}
+	}
+}
+
+func TestParse_Number(t *testing.T) {
+	cases := []struct {
+		input string
+		Type  JSONType
+	}{
+		{`0`, JSONInt},
+		{`-12`, JSONInt},
+		{`1.5`, JSONFloat},
+		{`1e-10`, JSONFloat},
+		{`-2.5E+3`, JSONFloat},
+		{`123456789012345678901234567890`, JSONInt},
+		{`1e400`, JSONFloat},
+	}
+	for _, c := range cases {
+		json, err := Parse([]byte("["+c.input+"]"), ParseOptions{Strict: true})
+		if err != nil {
+			t.Fatalf("%q: %v", c.input, err)
+		}
+		node := json.Get(0)
+		if node.Type != c.Type || string(node.Bytes) != c.input {
+			t.Fatalf("%q: got %q of type %d", c.input, node.Bytes, node.Type)
+		}
+		if string(json.Marshal()) != "["+c.input+"]" {
+			t.Fatalf("%q: number was changed by Marshal", c.input)
+		}
+	}
+	val, _ := Unmarshal([]byte(`1e-10`)).ValueFloat()
+	if val != 1e-10 {
+		t.Fatal("wrong value", val)
+	}
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	for _, input := range []string{``, `{"a": 1,`, `[tru]`} {
+		if json := Unmarshal([]byte(input)); json.Type != JSONInvalid || json.Len() != 0 {
+			t.Fatalf("%q: expected invalid node, got %v", input, json)
+		}
+	}
+}
+
+func TestParse_Depth(t *testing.T) {
+	deep := bytes.Repeat([]byte("["), 3000000)
+	if _, err := Parse(deep); err == nil || err.(*SyntaxError).Offset != maxDepth || err.(*SyntaxError).Expected != "at most 10000 nested arrays and objects" {
+		t.Fatal("expected depth error", err)
+	}
+	if _, err := NewDecoder(bytes.NewReader(append(bytes.Repeat([]byte(`{"a":`), 20000), '1'))).Decode(); err == nil {
+		t.Fatal("expected depth error from Decoder")
+	}
+	if _, err := NewLineReader(bytes.NewReader(append(deep, '\n'))).Read(); err == nil {
+		t.Fatal("expected depth error from LineReader")
+	}
+	if json := Unmarshal(deep); json.Type != JSONInvalid {
+		t.Fatal("expected invalid node")
+	}
+
+	nested := append(bytes.Repeat([]byte("["), maxDepth), bytes.Repeat([]byte("]"), maxDepth)...)
+	json, err := Parse(nested)
+	if err != nil || json.Type != JSONArray {
+		t.Fatal("expected nesting up to maxDepth to parse", err)
+	}
+}
+
+func TestGoJSON_KeyOrder(t *testing.T) {
+	json := Unmarshal([]byte(`{"z": 1, "a": {"y": true, "b": null}, "m": [], "a": 2}`))
+	if out := string(json.Marshal()); out != `{"z":1,"a":2,"m":[]}` {
+		t.Fatal("wrong order", out)
+	}
+	json.SetInt("c", 3)
+	json.Delete("z")
+	json.Update(Unmarshal([]byte(`{"x": 4, "m": 5}`)))
+	json.Map["b"] = &GoJSON{Type: JSONNull, Bytes: []byte("null")}
+	if keys := fmt.Sprint(json.Keys()); keys != "[a m c x b]" {
+		t.Fatal("wrong keys", keys)
+	}
+	if out := string(json.Marshal()); out != `{"a":2,"m":5,"c":3,"x":4,"b":null}` {
+		t.Fatal("wrong order", out)
+	}
+	if out := string(json.MarshalWith(MarshalOptions{SortKeys: true})); out != `{"a":2,"b":null,"c":3,"m":5,"x":4}` {
+		t.Fatal("wrong sorted order", out)
+	}
+}
+
+func TestGoJSON_MarshalIndent(t *testing.T) {
+	json := Unmarshal([]byte(`{"a": {"b": [1, 2], "c": {}, "d": []}, "e": [[true, null], "x"]}`))
+	expected := `{
+>  "a": {
+>    "b": [
+>      1,
+>      2
+>    ],
+>    "c": {},
+>    "d": []
+>  },
+>  "e": [
+>    [
+>      true,
+>      null
+>    ],
+>    "x"
+>  ]
+>}`
+	if out := string(json.MarshalIndent(">", "  ")); out != expected {
+		t.Fatal("wrong output", out)
+	}
+	expected = `{
+	"a": {
+		"b": [1, 2],
+		"c": {},
+		"d": []
+	},
+	"e": [
+		[true, null],
+		"x"
+	]
+}`
+	out := string(json.MarshalWith(MarshalOptions{Indent: "\t", ColonSpace: true, InlineArrayLimit: 13}))
+	if out != expected {
+		t.Fatal("wrong output", out)
+	}
+	if out := string(Unmarshal([]byte(`[{"a": 1}]`)).MarshalIndent("", " ")); out != "[\n {\n  \"a\": 1\n }\n]" {
+		t.Fatal("wrong output", out)
+	}
+}
+
+func TestGoJSON_MarshalEscape(t *testing.T) {
+	json := NewObject()
+	json.SetString("k\"e\ny", "<a&b>\x00\x1f\x7f\u2028é😀\xff")
+	out := json.Marshal()
+	if string(out) != `{"k\"e\ny":"<a&b>\u0000\u001f\u007f\u2028é😀\ufffd"}` {
+		t.Fatal("wrong output", string(out))
+	}
+	if _, err := Parse(out, ParseOptions{Strict: true}); err != nil {
+		t.Fatal(err)
+	}
+	out = json.MarshalWith(MarshalOptions{EscapeHTML: true, ASCIIOnly: true})
+	if string(out) != `{"k\"e\ny":"\u003ca\u0026b\u003e\u0000\u001f\u007f\u2028\u00e9\ud83d\ude00\ufffd"}` {
+		t.Fatal("wrong output", string(out))
+	}
+	val, _ := Unmarshal(out).Get("k\"e\ny").ValueString()
+	if val != "<a&b>\x00\x1f\x7f\u2028é😀\uFFFD" {
+		t.Fatalf("wrong round trip %q", val)
+	}
+}
+
 func BenchmarkMarshal(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		Unmarshal(data)
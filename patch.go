@@ -0,0 +1,221 @@
+package gojson
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTestFailed is returned when value of JSON Patch "test" operation differs from the document
+var ErrTestFailed = errors.New("test failed: values differ")
+
+// PatchError describes JSON Patch operation that is malformed or can't be applied
+type PatchError struct {
+	Index int    // position of the operation in the patch
+	Op    string // "add", "remove", ... or empty if the operation has no op member
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("patch operation %d: %v", e.Index, e.Err)
+	}
+	return fmt.Sprintf("patch operation %d (%s): %v", e.Index, e.Op, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// patchOp is validated JSON Patch operation
+type patchOp struct {
+	op    string
+	path  []string
+	from  []string
+	value *GoJSON
+}
+
+// ApplyPatch applies RFC 6902 JSON Patch to doc, patch is an array of operations
+// add, remove, replace, move, copy and test, it is atomic: if any operation fails
+// *PatchError is returned and doc is left unchanged
+func ApplyPatch(doc *GoJSON, patch *GoJSON) error {
+	if patch.Type != JSONArray {
+		return errors.New("patch must be an array of operations")
+	}
+	ops := make([]patchOp, len(patch.Array))
+	for i, node := range patch.Array {
+		op, err := parsePatchOp(node)
+		if err != nil {
+			return &PatchError{Index: i, Op: op.op, Err: err}
+		}
+		ops[i] = op
+	}
+	// operations are tried on a copy first so doc keeps its nodes when the patch is applied
+	if err := applyPatchOps(doc.Clone(), ops); err != nil {
+		return err
+	}
+	return applyPatchOps(doc, ops)
+}
+
+// parsePatchOp validates members of operation object
+func parsePatchOp(node *GoJSON) (op patchOp, err error) {
+	if node.Type != JSONObject {
+		return op, errors.New("operation must be an object")
+	}
+	if name := node.Map["op"]; name != nil && name.Type == JSONString {
+		op.op = string(name.Bytes)
+	} else {
+		return op, errors.New(`missing or non string "op"`)
+	}
+	pointer := func(member string) ([]string, error) {
+		value := node.Map[member]
+		if value == nil || value.Type != JSONString {
+			return nil, fmt.Errorf("missing or non string %q", member)
+		}
+		return ParsePointer(string(value.Bytes))
+	}
+	if op.path, err = pointer("path"); err != nil {
+		return op, err
+	}
+	switch op.op {
+	case "add", "replace", "test":
+		if op.value = node.Map["value"]; op.value == nil {
+			return op, errors.New(`missing "value"`)
+		}
+	case "move", "copy":
+		if op.from, err = pointer("from"); err != nil {
+			return op, err
+		}
+		if op.op == "move" && len(op.from) < len(op.path) && hasPrefix(op.path, op.from) {
+			return op, errors.New("cannot move a value into one of its children")
+		}
+	case "remove":
+	default:
+		return op, fmt.Errorf("unknown operation %q", op.op)
+	}
+	return op, nil
+}
+
+// applyPatchOps applies validated operations to doc one by one
+func applyPatchOps(doc *GoJSON, ops []patchOp) error {
+	for i, op := range ops {
+		var err error
+		switch op.op {
+		case "add":
+			err = patchAdd(doc, op.path, op.value.Clone())
+		case "remove":
+			_, err = patchRemove(doc, op.path)
+		case "replace":
+			err = patchReplace(doc, op.path, op.value.Clone())
+		case "move":
+			var value *GoJSON
+			if len(op.from) == len(op.path) && hasPrefix(op.path, op.from) {
+				_, err = doc.GetPointer(FormatPointer(op.from...))
+			} else if value, err = patchRemove(doc, op.from); err == nil {
+				err = patchAdd(doc, op.path, value)
+			}
+		case "copy":
+			var value *GoJSON
+			if value, err = doc.GetPointer(FormatPointer(op.from...)); err == nil {
+				err = patchAdd(doc, op.path, value.Clone())
+			}
+		case "test":
+			var value *GoJSON
+			if value, err = doc.GetPointer(FormatPointer(op.path...)); err == nil && !nodesEqual(value, op.value) {
+				err = ErrTestFailed
+			}
+		}
+		if err != nil {
+			return &PatchError{Index: i, Op: op.op, Err: err}
+		}
+	}
+	return nil
+}
+
+// patchAdd inserts value into array or sets object member, the whole document is replaced by empty path
+func patchAdd(doc *GoJSON, tokens []string, value *GoJSON) error {
+	if len(tokens) == 0 {
+		doc.assign(value)
+		return nil
+	}
+	parent, err := doc.walk(tokens[:len(tokens)-1], false)
+	if err != nil {
+		return err
+	}
+	key := tokens[len(tokens)-1]
+	switch parent.Type {
+	case JSONObject:
+		parent.detach(parent.Map[key])
+		parent.Set(key, value)
+	case JSONArray:
+		index, err := arrayIndex(key, len(parent.Array), true)
+		if err != nil {
+			return fmt.Errorf("%s: %w", FormatPointer(tokens...), err)
+		}
+		parent.Set(index, value)
+	default:
+		return fmt.Errorf("%s: cannot add member to non object/array", FormatPointer(tokens...))
+	}
+	return nil
+}
+
+// patchReplace replaces existing value keeping its position in the object or array
+func patchReplace(doc *GoJSON, tokens []string, value *GoJSON) error {
+	if len(tokens) == 0 {
+		doc.assign(value)
+		return nil
+	}
+	parent, err := doc.walk(tokens[:len(tokens)-1], false)
+	if err != nil {
+		return err
+	}
+	key := tokens[len(tokens)-1]
+	old, err := parent.child(key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", FormatPointer(tokens...), err)
+	}
+	parent.detach(old)
+	if parent.Type == JSONArray {
+		index, _ := arrayIndex(key, len(parent.Array), false)
+		parent.Array[index] = value
+	} else {
+		parent.Map[key] = value
+	}
+	value.parent = parent
+	return nil
+}
+
+// patchRemove removes referenced value from its parent and returns it
+func patchRemove(doc *GoJSON, tokens []string) (*GoJSON, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	parent, err := doc.walk(tokens[:len(tokens)-1], false)
+	if err != nil {
+		return nil, err
+	}
+	key := tokens[len(tokens)-1]
+	value, err := parent.child(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", FormatPointer(tokens...), err)
+	}
+	if parent.Type == JSONArray {
+		index, _ := arrayIndex(key, len(parent.Array), false)
+		parent.Delete(index)
+	} else {
+		parent.Delete(key)
+	}
+	return value, nil
+}
+
+// hasPrefix reports whether tokens start with prefix
+func hasPrefix(tokens, prefix []string) bool {
+	if len(prefix) > len(tokens) {
+		return false
+	}
+	for i := range prefix {
+		if tokens[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
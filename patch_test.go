@@ -0,0 +1,106 @@
+package gojson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	doc := `{"a": {"b": [1, 2, 3]}, "c": "x", "d": null}`
+	cases := map[string]string{
+		`[{"op": "add", "path": "/a/b/1", "value": 9}]`:                                                              `{"a":{"b":[1,9,2,3]},"c":"x","d":null}`,
+		`[{"op": "add", "path": "/a/b/3", "value": 9}]`:                                                              `{"a":{"b":[1,2,3,9]},"c":"x","d":null}`,
+		`[{"op": "add", "path": "/a/b/-", "value": [9]}]`:                                                            `{"a":{"b":[1,2,3,[9]]},"c":"x","d":null}`,
+		`[{"op": "add", "path": "/c", "value": {"e": 1}}, {"op": "add", "path": "/f", "value": 2}]`:                  `{"a":{"b":[1,2,3]},"c":{"e":1},"d":null,"f":2}`,
+		`[{"op": "add", "path": "", "value": [1]}]`:                                                                  `[1]`,
+		`[{"op": "remove", "path": "/a/b/0"}, {"op": "remove", "path": "/d"}]`:                                       `{"a":{"b":[2,3]},"c":"x"}`,
+		`[{"op": "replace", "path": "/a", "value": 1}, {"op": "replace", "path": "/d", "value": 2}]`:                 `{"a":1,"c":"x","d":2}`,
+		`[{"op": "replace", "path": "/a/b/2", "value": "z"}]`:                                                        `{"a":{"b":[1,2,"z"]},"c":"x","d":null}`,
+		`[{"op": "move", "from": "/a/b/0", "path": "/a/b/-"}]`:                                                       `{"a":{"b":[2,3,1]},"c":"x","d":null}`,
+		`[{"op": "move", "from": "/c", "path": "/a/c"}, {"op": "move", "from": "/d", "path": "/d"}]`:                 `{"a":{"b":[1,2,3],"c":"x"},"d":null}`,
+		`[{"op": "copy", "from": "/a/b", "path": "/a/b/0"}, {"op": "remove", "path": "/a/b/0/0"}]`:                   `{"a":{"b":[[2,3],1,2,3]},"c":"x","d":null}`,
+		`[{"op": "test", "path": "/a", "value": {"b": [1.0, 2, 3e0]}}, {"op": "test", "path": "/d", "value": null}]`: `{"a":{"b":[1,2,3]},"c":"x","d":null}`,
+		`[{"op": "add", "path": "/~1x~0", "value": 1, "extra": true}]`:                                               `{"a":{"b":[1,2,3]},"c":"x","d":null,"/x~":1}`,
+	}
+	for patch, expected := range cases {
+		json := Unmarshal([]byte(doc))
+		if err := ApplyPatch(json, Unmarshal([]byte(patch))); err != nil {
+			t.Fatal(patch, err)
+		}
+		if out := string(json.Marshal()); out != expected {
+			t.Fatalf("%s: expected %s, got %s", patch, expected, out)
+		}
+	}
+
+	invalid := []string{
+		`{"op": "add", "path": "/a", "value": 1}`,
+		`[1]`,
+		`[{"path": "/a"}]`,
+		`[{"op": "add", "path": "/a"}]`,
+		`[{"op": "nop", "path": "/a"}]`,
+		`[{"op": "move", "path": "/a"}]`,
+		`[{"op": "remove", "path": "a"}]`,
+		`[{"op": "remove", "path": "/x"}]`,
+		`[{"op": "remove", "path": ""}]`,
+		`[{"op": "remove", "path": "/a/b/3"}]`,
+		`[{"op": "add", "path": "/a/b/4", "value": 1}]`,
+		`[{"op": "add", "path": "/a/b/01", "value": 1}]`,
+		`[{"op": "add", "path": "/x/y", "value": 1}]`,
+		`[{"op": "add", "path": "/c/y", "value": 1}]`,
+		`[{"op": "replace", "path": "/a/b/-", "value": 1}]`,
+		`[{"op": "replace", "path": "/x", "value": 1}]`,
+		`[{"op": "move", "from": "/a", "path": "/a/b/0"}]`,
+		`[{"op": "copy", "from": "/x", "path": "/y"}]`,
+		`[{"op": "test", "path": "/c", "value": "y"}]`,
+		`[{"op": "remove", "path": "/c"}, {"op": "add", "path": "/a/b/0", "value": 0}, {"op": "test", "path": "/d", "value": 1}]`,
+	}
+	for _, patch := range invalid {
+		json := Unmarshal([]byte(doc))
+		b := json.Get("a").Get("b")
+		if err := ApplyPatch(json, Unmarshal([]byte(patch))); err == nil {
+			t.Fatal("expected error for", patch)
+		}
+		if out := string(json.Marshal()); out != `{"a":{"b":[1,2,3]},"c":"x","d":null}` {
+			t.Fatalf("%s: document was modified %s", patch, out)
+		}
+		if json.Get("a").Get("b") != b || b.Pointer() != "/a/b" {
+			t.Fatal(patch, "nodes of the document were replaced")
+		}
+	}
+}
+
+func TestApplyPatch_Errors(t *testing.T) {
+	json := Unmarshal([]byte(`{"a": [1]}`))
+	patch := Unmarshal([]byte(`[{"op": "test", "path": "/a/0", "value": 1}, {"op": "test", "path": "/a/0", "value": 2}]`))
+	err := ApplyPatch(json, patch)
+	var patchErr *PatchError
+	if !errors.As(err, &patchErr) || patchErr.Index != 1 || patchErr.Op != "test" || !errors.Is(err, ErrTestFailed) {
+		t.Fatal("unexpected error", err)
+	}
+	err = ApplyPatch(json, Unmarshal([]byte(`[{"op": "remove", "path": "/a/1"}]`)))
+	if !errors.Is(err, ErrPointerNotFound) || err.Error() != "patch operation 0 (remove): /a/1: pointer references nonexistent value" {
+		t.Fatal("unexpected error", err)
+	}
+
+	// added values are copies and nodes keep correct parents
+	value := Unmarshal([]byte(`{"b": 2}`))
+	patch = NewArray()
+	op := NewObject()
+	op.Set("op", stringNode("add"))
+	op.Set("path", stringNode("/a/0"))
+	op.Set("value", value)
+	patch.Set(-1, op)
+	if err = ApplyPatch(json, patch); err != nil {
+		t.Fatal(err)
+	}
+	value.Set("c", numberNode(3))
+	if out := string(json.Marshal()); out != `{"a":[{"b":2},1]}` {
+		t.Fatal("patch value is shared with the document", out)
+	}
+	if p := json.Get("a").Get(0).Get("b").Pointer(); p != "/a/0/b" {
+		t.Fatal("wrong pointer", p)
+	}
+	if p := json.Get("a").Get(1).Pointer(); p != "/a/1" {
+		t.Fatal("wrong pointer", p)
+	}
+}
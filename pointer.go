@@ -0,0 +1,219 @@
+package gojson
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrPointerNotFound is returned when JSON Pointer references nonexistent value
+var ErrPointerNotFound = errors.New("pointer references nonexistent value")
+
+// ParsePointer splits RFC 6901 JSON Pointer into unescaped reference tokens
+// empty pointer references the whole document and has no tokens
+func ParsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("pointer %q must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || token[j+1] != '0' && token[j+1] != '1') {
+				return nil, fmt.Errorf("pointer %q has invalid escape", pointer)
+			}
+		}
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// FormatPointer escapes reference tokens and joins them into JSON Pointer
+func FormatPointer(tokens ...string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1))
+	}
+	return b.String()
+}
+
+// GetPointer returns node referenced by RFC 6901 JSON Pointer like "/a/b/3"
+func (g *GoJSON) GetPointer(pointer string) (*GoJSON, error) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	node := g
+	for i, token := range tokens {
+		if node, err = node.child(token); err != nil {
+			return nil, fmt.Errorf("%s: %w", FormatPointer(tokens[:i+1]...), err)
+		}
+	}
+	return node, nil
+}
+
+// SetPointer sets value at JSON Pointer, object members are added or replaced,
+// array elements are replaced, "-" or index equal to array length appends
+// if createMissing is set missing intermediate objects are created
+func (g *GoJSON) SetPointer(pointer string, value *GoJSON, createMissing bool) error {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		g.assign(value)
+		return nil
+	}
+	parent, err := g.walk(tokens[:len(tokens)-1], createMissing)
+	if err != nil {
+		return err
+	}
+	key := tokens[len(tokens)-1]
+	switch parent.Type {
+	case JSONObject:
+		parent.Set(key, value)
+	case JSONArray:
+		index, err := arrayIndex(key, len(parent.Array), true)
+		if err != nil {
+			return fmt.Errorf("%s: %w", pointer, err)
+		}
+		if index == len(parent.Array) {
+			parent.Array = append(parent.Array, value)
+		} else {
+			parent.detach(parent.Array[index])
+			parent.Array[index] = value
+		}
+		value.parent = parent
+	default:
+		return fmt.Errorf("%s: cannot set member of non object/array", pointer)
+	}
+	return nil
+}
+
+// DeletePointer removes node referenced by JSON Pointer from its object or array
+func (g *GoJSON) DeletePointer(pointer string) error {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return errors.New("cannot delete the whole document")
+	}
+	parent, err := g.walk(tokens[:len(tokens)-1], false)
+	if err != nil {
+		return err
+	}
+	key := tokens[len(tokens)-1]
+	if _, err = parent.child(key); err != nil {
+		return fmt.Errorf("%s: %w", pointer, err)
+	}
+	if parent.Type == JSONArray {
+		index, _ := arrayIndex(key, len(parent.Array), false)
+		parent.Delete(index)
+	} else {
+		parent.Delete(key)
+	}
+	return nil
+}
+
+// Pointer returns JSON Pointer of the node from the root of its tree
+// the tree is the one node was last attached to by parsing, Set or SetPointer
+func (g *GoJSON) Pointer() string {
+	var tokens []string
+	for node := g; node.parent != nil; node = node.parent {
+		token, found := node.parent.tokenOf(node)
+		if !found {
+			// node was removed bypassing Delete
+			break
+		}
+		tokens = append(tokens, token)
+	}
+	for i, j := 0, len(tokens)-1; i < j; i, j = i+1, j-1 {
+		tokens[i], tokens[j] = tokens[j], tokens[i]
+	}
+	return FormatPointer(tokens...)
+}
+
+// walk follows tokens from g, creating missing objects if create is set
+func (g *GoJSON) walk(tokens []string, create bool) (*GoJSON, error) {
+	node := g
+	for i, token := range tokens {
+		next, err := node.child(token)
+		if err == ErrPointerNotFound && create && node.Type == JSONObject {
+			next = NewObject()
+			node.Set(token, next)
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", FormatPointer(tokens[:i+1]...), err)
+		}
+		node = next
+	}
+	return node, nil
+}
+
+// child returns member of the object or element of the array referenced by token
+func (g *GoJSON) child(token string) (*GoJSON, error) {
+	switch g.Type {
+	case JSONObject:
+		if value, found := g.Map[token]; found {
+			return value, nil
+		}
+		return nil, ErrPointerNotFound
+	case JSONArray:
+		index, err := arrayIndex(token, len(g.Array), false)
+		if err != nil {
+			return nil, err
+		}
+		return g.Array[index], nil
+	}
+	return nil, ErrPointerNotFound
+}
+
+// tokenOf returns reference token of the direct child node
+func (g *GoJSON) tokenOf(node *GoJSON) (string, bool) {
+	switch g.Type {
+	case JSONObject:
+		for _, key := range g.orderedKeys() {
+			if g.Map[key] == node {
+				return key, true
+			}
+		}
+	case JSONArray:
+		for i, value := range g.Array {
+			if value == node {
+				return strconv.Itoa(i), true
+			}
+		}
+	}
+	return "", false
+}
+
+// arrayIndex parses array index token, it has to reference an existing element
+// unless allowEnd is set, then index equal to length and "-" are valid as well
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" {
+		if !allowEnd {
+			return 0, ErrPointerNotFound
+		}
+		return length, nil
+	}
+	if token == "" || len(token) > 1 && token[0] == '0' {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	for i := 0; i < len(token); i++ {
+		if !isDigit(token[i]) {
+			return 0, fmt.Errorf("invalid array index %q", token)
+		}
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index > length || index == length && !allowEnd {
+		return 0, ErrPointerNotFound
+	}
+	return index, nil
+}
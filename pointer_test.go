@@ -0,0 +1,77 @@
+package gojson
+
+import (
+	"testing"
+)
+
+func TestGoJSON_GetPointer(t *testing.T) {
+	json := Unmarshal([]byte(`{"a": {"b": [1, {"c/d": true, "e~f": 2}]}, "": 3}`))
+	cases := map[string]string{
+		"":            string(json.Marshal()),
+		"/a/b/0":      "1",
+		"/a/b/1/c~1d": "true",
+		"/a/b/1/e~0f": "2",
+		"/":           "3",
+	}
+	for pointer, expected := range cases {
+		node, err := json.GetPointer(pointer)
+		if err != nil {
+			t.Fatal(pointer, err)
+		}
+		if out := string(node.MarshalWith(MarshalOptions{})); out != expected {
+			t.Fatalf("%s: expected %s, got %s", pointer, expected, out)
+		}
+		if node.Pointer() != pointer {
+			t.Fatalf("%s: wrong Pointer() %s", pointer, node.Pointer())
+		}
+	}
+	for _, pointer := range []string{"a", "/x", "/a/b/2", "/a/b/-", "/a/b/01", "/a/b/0/x", "/a/~2"} {
+		if _, err := json.GetPointer(pointer); err == nil {
+			t.Fatal("expected error for", pointer)
+		}
+	}
+}
+
+func TestGoJSON_SetPointer(t *testing.T) {
+	json := Unmarshal([]byte(`{"a": [1, 2]}`))
+	if err := json.SetPointer("/a/0", Unmarshal([]byte(`"x"`)), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.SetPointer("/a/-", Unmarshal([]byte(`3`)), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.SetPointer("/a/3", Unmarshal([]byte(`4`)), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.SetPointer("/a/9", Unmarshal([]byte(`4`)), false); err == nil {
+		t.Fatal("expected error for index out of range")
+	}
+	if err := json.SetPointer("/b/c/d", Unmarshal([]byte(`true`)), false); err == nil {
+		t.Fatal("expected error for missing parent")
+	}
+	value := Unmarshal([]byte(`true`))
+	if err := json.SetPointer("/b/c~1/d", value, true); err != nil {
+		t.Fatal(err)
+	}
+	if value.Pointer() != "/b/c~1/d" {
+		t.Fatal("wrong pointer", value.Pointer())
+	}
+	if out := string(json.Marshal()); out != `{"a":["x",2,3,4],"b":{"c/":{"d":true}}}` {
+		t.Fatal("wrong json", out)
+	}
+	if err := json.DeletePointer("/a/1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.DeletePointer("/b/c~1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.DeletePointer("/b/x"); err == nil {
+		t.Fatal("expected error for missing key")
+	}
+	if out := string(json.Marshal()); out != `{"a":["x",3,4],"b":{}}` {
+		t.Fatal("wrong json", out)
+	}
+	if node, _ := json.GetPointer("/a/2"); node.Pointer() != "/a/2" {
+		t.Fatal("wrong pointer", node.Pointer())
+	}
+}
@@ -0,0 +1,535 @@
+// Package schema validates gojson documents against JSON Schema draft 2020-12
+package schema
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+
+	"github.com/FanFani4/gojson"
+)
+
+// CompileError describes invalid schema
+type CompileError struct {
+	Pointer string // JSON Pointer of the invalid keyword in the schema
+	Msg     string
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("invalid schema at %q: %s", e.Pointer, e.Msg)
+}
+
+// Schema is compiled JSON Schema, it is safe for concurrent use
+type Schema struct {
+	root *node
+}
+
+// Compile compiles JSON Schema document, $ref and $dynamicRef have to reference the document itself
+// or resources identified by $id inside it, $schema is ignored and draft 2020-12 vocabularies are used
+// format is validated for known formats instead of being only an annotation
+func Compile(doc *gojson.GoJSON) (*Schema, error) {
+	c := &compiler{
+		locations: make(map[*gojson.GoJSON]location),
+		resources: make(map[string]*gojson.GoJSON),
+		anchors:   make(map[string]*gojson.GoJSON),
+		dynamic:   make(map[*gojson.GoJSON]map[string]*gojson.GoJSON),
+		nodes:     make(map[*gojson.GoJSON]*node),
+	}
+	c.resources[""] = doc
+	if err := c.scan(doc, "", nil); err != nil {
+		return nil, err
+	}
+	root, err := c.compile(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+// MustCompile is like Compile but panics if the schema is invalid
+func MustCompile(doc *gojson.GoJSON) *Schema {
+	s, err := Compile(doc)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// node is compiled schema object or boolean schema
+type node struct {
+	pointer string // location in the schema document
+	boolean *bool  // set for true and false schemas
+
+	anchors    map[string]*node // dynamic anchors of the resource, set if the schema is a resource root
+	ref        *node
+	dynamicRef *node
+	dynamic    string // anchor name if $dynamicRef target has matching $dynamicAnchor
+
+	types    []string
+	enum     []*gojson.GoJSON
+	constant *gojson.GoJSON
+	format   string
+
+	multipleOf, maximum, exclusiveMaximum, minimum, exclusiveMinimum *big.Rat
+
+	maxLength, minLength         int // -1 if absent
+	maxItems, minItems           int
+	maxProperties, minProperties int
+	minContains, maxContains     int
+	pattern                      *regexp.Regexp
+	uniqueItems                  bool
+	required                     []string
+	dependentRequired            []dependency
+
+	allOf, anyOf, oneOf    []*node
+	not, ifSchema          *node
+	thenSchema, elseSchema *node
+	dependentSchemas       []namedNode
+
+	prefixItems           []*node
+	items, contains       *node
+	properties            []namedNode
+	patternProperties     []patternNode
+	additionalProperties  *node
+	propertyNames         *node
+	unevaluatedItems      *node
+	unevaluatedProperties *node
+}
+
+type dependency struct {
+	name     string
+	required []string
+}
+
+type namedNode struct {
+	name string
+	node *node
+}
+
+type patternNode struct {
+	pattern *regexp.Regexp
+	node    *node
+}
+
+// location of a subschema, base is URI of the resource it belongs to
+type location struct {
+	base    string
+	pointer string
+}
+
+type compiler struct {
+	locations map[*gojson.GoJSON]location
+	resources map[string]*gojson.GoJSON // by absolute URI without fragment
+	anchors   map[string]*gojson.GoJSON // by URI with anchor name as fragment
+	dynamic   map[*gojson.GoJSON]map[string]*gojson.GoJSON
+	nodes     map[*gojson.GoJSON]*node
+}
+
+// keywords with a subschema, an object of subschemas and an array of subschemas
+var (
+	schemaKeywords = []string{"additionalProperties", "propertyNames", "items", "contains", "not", "if", "then", "else",
+		"unevaluatedItems", "unevaluatedProperties"}
+	schemaMapKeywords   = []string{"$defs", "definitions", "properties", "patternProperties", "dependentSchemas"}
+	schemaArrayKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+)
+
+// scan records location of every subschema and registers resources and anchors
+func (c *compiler) scan(doc *gojson.GoJSON, base string, tokens []string) error {
+	pointer := gojson.FormatPointer(tokens...)
+	if doc.Type != gojson.JSONObject {
+		c.locations[doc] = location{base: base, pointer: pointer}
+		return nil
+	}
+	if id := doc.Map["$id"]; id != nil {
+		if id.Type != gojson.JSONString {
+			return &CompileError{Pointer: pointer + "/$id", Msg: "$id must be a string"}
+		}
+		uri, err := resolveURI(base, string(id.Bytes))
+		if err != nil {
+			return &CompileError{Pointer: pointer + "/$id", Msg: err.Error()}
+		}
+		uri.Fragment, uri.RawFragment = "", ""
+		base = uri.String()
+		c.resources[base] = doc
+	}
+	c.locations[doc] = location{base: base, pointer: pointer}
+	for _, keyword := range []string{"$anchor", "$dynamicAnchor"} {
+		anchor := doc.Map[keyword]
+		if anchor == nil {
+			continue
+		}
+		if anchor.Type != gojson.JSONString {
+			return &CompileError{Pointer: pointer + "/" + keyword, Msg: keyword + " must be a string"}
+		}
+		c.anchors[base+"#"+string(anchor.Bytes)] = doc
+		if keyword == "$dynamicAnchor" {
+			resource := c.resources[base]
+			if c.dynamic[resource] == nil {
+				c.dynamic[resource] = make(map[string]*gojson.GoJSON)
+			}
+			c.dynamic[resource][string(anchor.Bytes)] = doc
+		}
+	}
+	child := func(value *gojson.GoJSON, path ...string) error {
+		return c.scan(value, base, append(tokens[:len(tokens):len(tokens)], path...))
+	}
+	for _, keyword := range schemaKeywords {
+		if value := doc.Map[keyword]; value != nil {
+			if err := child(value, keyword); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyword := range schemaMapKeywords {
+		if value := doc.Map[keyword]; value != nil && value.Type == gojson.JSONObject {
+			for _, name := range value.Keys() {
+				if err := child(value.Map[name], keyword, name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, keyword := range schemaArrayKeywords {
+		if value := doc.Map[keyword]; value != nil && value.Type == gojson.JSONArray {
+			for i, item := range value.Array {
+				if err := child(item, keyword, fmt.Sprint(i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolveURI resolves reference against base URI
+func resolveURI(base, ref string) (*url.URL, error) {
+	refURI, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	baseURI, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	return baseURI.ResolveReference(refURI), nil
+}
+
+// resolve returns schema referenced by $ref from the schema at loc
+func (c *compiler) resolve(loc location, ref string) (*gojson.GoJSON, error) {
+	uri, err := resolveURI(loc.base, ref)
+	if err != nil {
+		return nil, err
+	}
+	fragment := uri.Fragment
+	uri.Fragment, uri.RawFragment = "", ""
+	resource, found := c.resources[uri.String()]
+	if !found {
+		return nil, fmt.Errorf("cannot resolve %q, only references inside the schema are supported", ref)
+	}
+	switch {
+	case fragment == "":
+		return resource, nil
+	case fragment[0] == '/':
+		target, err := resource.GetPointer(fragment)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve %q: %v", ref, err)
+		}
+		if _, found := c.locations[target]; !found {
+			tokens, _ := gojson.ParsePointer(c.locations[resource].pointer + fragment)
+			if err = c.scan(target, uri.String(), tokens); err != nil {
+				return nil, err
+			}
+		}
+		return target, nil
+	}
+	if target, found := c.anchors[uri.String()+"#"+fragment]; found {
+		return target, nil
+	}
+	return nil, fmt.Errorf("cannot resolve %q, anchor %q not found", ref, fragment)
+}
+
+// compile compiles scanned schema, nodes are cached so recursive references end up in the same node
+func (c *compiler) compile(doc *gojson.GoJSON) (*node, error) {
+	if n, found := c.nodes[doc]; found {
+		return n, nil
+	}
+	loc := c.locations[doc]
+	n := &node{pointer: loc.pointer, maxLength: -1, minLength: -1, maxItems: -1, minItems: -1,
+		maxProperties: -1, minProperties: -1, minContains: -1, maxContains: -1}
+	c.nodes[doc] = n
+	switch doc.Type {
+	case gojson.JSONBool:
+		value, _ := doc.ValueBool()
+		n.boolean = &value
+		return n, nil
+	case gojson.JSONObject:
+	default:
+		return nil, &CompileError{Pointer: loc.pointer, Msg: "schema must be an object or a boolean"}
+	}
+	k := keywords{c: c, n: n, doc: doc, loc: loc}
+	if c.resources[loc.base] == doc {
+		n.anchors = make(map[string]*node)
+		for name, anchor := range c.dynamic[doc] {
+			if n.anchors[name], k.err = c.compile(anchor); k.err != nil {
+				return nil, k.err
+			}
+		}
+	}
+	k.compile()
+	if k.err != nil {
+		return nil, k.err
+	}
+	return n, nil
+}
+
+// keywords compiles keywords of one schema object, the first error is kept in err
+type keywords struct {
+	c   *compiler
+	n   *node
+	doc *gojson.GoJSON
+	loc location
+	err error
+}
+
+func (k *keywords) fail(keyword, format string, args ...interface{}) {
+	if k.err == nil {
+		k.err = &CompileError{Pointer: k.loc.pointer + "/" + keyword, Msg: fmt.Sprintf(format, args...)}
+	}
+}
+
+// value returns keyword value if it has expected type
+func (k *keywords) value(keyword string, typ gojson.JSONType, name string) *gojson.GoJSON {
+	value := k.doc.Map[keyword]
+	if value == nil {
+		return nil
+	}
+	if value.Type != typ {
+		k.fail(keyword, "%s must be %s", keyword, name)
+		return nil
+	}
+	return value
+}
+
+func (k *keywords) schema(keyword string) *node {
+	value := k.doc.Map[keyword]
+	if value == nil || k.err != nil {
+		return nil
+	}
+	n, err := k.c.compile(value)
+	if err != nil {
+		k.err = err
+	}
+	return n
+}
+
+func (k *keywords) schemaArray(keyword string) []*node {
+	value := k.value(keyword, gojson.JSONArray, "an array of schemas")
+	if value == nil {
+		return nil
+	}
+	if len(value.Array) == 0 {
+		k.fail(keyword, "%s must not be empty", keyword)
+	}
+	nodes := make([]*node, len(value.Array))
+	for i, item := range value.Array {
+		if k.err != nil {
+			return nil
+		}
+		nodes[i], k.err = k.c.compile(item)
+	}
+	return nodes
+}
+
+func (k *keywords) schemaMap(keyword string) []namedNode {
+	value := k.value(keyword, gojson.JSONObject, "an object of schemas")
+	if value == nil {
+		return nil
+	}
+	nodes := make([]namedNode, 0, len(value.Map))
+	for _, name := range value.Keys() {
+		if k.err != nil {
+			return nil
+		}
+		n, err := k.c.compile(value.Map[name])
+		k.err = err
+		nodes = append(nodes, namedNode{name: name, node: n})
+	}
+	return nodes
+}
+
+func (k *keywords) number(keyword string) *big.Rat {
+	value := k.doc.Map[keyword]
+	if value == nil {
+		return nil
+	}
+	if value.Type != gojson.JSONInt && value.Type != gojson.JSONFloat {
+		k.fail(keyword, "%s must be a number", keyword)
+		return nil
+	}
+	r, err := gojson.Number(value.Bytes).Rat()
+	if err != nil {
+		k.fail(keyword, "%v", err)
+	}
+	return r
+}
+
+// count returns non-negative integer keyword or -1 if it is absent
+func (k *keywords) count(keyword string) int {
+	value := k.doc.Map[keyword]
+	if value == nil {
+		return -1
+	}
+	i, err := gojson.Number(value.Bytes).Int64()
+	if value.Type != gojson.JSONInt && value.Type != gojson.JSONFloat || err != nil || i < 0 || int64(int(i)) != i {
+		k.fail(keyword, "%s must be a non-negative integer", keyword)
+		return -1
+	}
+	return int(i)
+}
+
+func (k *keywords) regexp(keyword string, pattern string) *regexp.Regexp {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		k.fail(keyword, "invalid pattern %q: %v", pattern, err)
+	}
+	return re
+}
+
+func (k *keywords) strings(keyword string) []string {
+	value := k.value(keyword, gojson.JSONArray, "an array of strings")
+	if value == nil {
+		return nil
+	}
+	strings := make([]string, len(value.Array))
+	for i, item := range value.Array {
+		if item.Type != gojson.JSONString {
+			k.fail(keyword, "%s must be an array of strings", keyword)
+			return nil
+		}
+		strings[i] = string(item.Bytes)
+	}
+	return strings
+}
+
+func (k *keywords) compile() {
+	n := k.n
+	for _, keyword := range []string{"$ref", "$dynamicRef"} {
+		ref := k.value(keyword, gojson.JSONString, "a string")
+		if ref == nil || k.err != nil {
+			continue
+		}
+		target, err := k.c.resolve(k.loc, string(ref.Bytes))
+		if err != nil {
+			k.fail(keyword, "%v", err)
+			continue
+		}
+		compiled, err := k.c.compile(target)
+		if err != nil {
+			k.err = err
+			continue
+		}
+		if keyword == "$ref" {
+			n.ref = compiled
+			continue
+		}
+		n.dynamicRef = compiled
+		// $dynamicRef behaves like $ref unless it points to a matching $dynamicAnchor
+		if uri, _ := url.Parse(string(ref.Bytes)); uri != nil && uri.Fragment != "" && uri.Fragment[0] != '/' {
+			if anchor := target.Map["$dynamicAnchor"]; anchor != nil && string(anchor.Bytes) == uri.Fragment {
+				n.dynamic = uri.Fragment
+			}
+		}
+	}
+
+	switch typ := k.doc.Map["type"]; {
+	case typ == nil:
+	case typ.Type == gojson.JSONString:
+		n.types = []string{string(typ.Bytes)}
+	default:
+		n.types = k.strings("type")
+	}
+	for _, typ := range n.types {
+		switch typ {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			k.fail("type", "unknown type %q", typ)
+		}
+	}
+	if enum := k.value("enum", gojson.JSONArray, "an array"); enum != nil {
+		for _, value := range enum.Array {
+			n.enum = append(n.enum, value.Clone())
+		}
+	}
+	if constant := k.doc.Map["const"]; constant != nil {
+		n.constant = constant.Clone()
+	}
+	if format := k.value("format", gojson.JSONString, "a string"); format != nil {
+		n.format = string(format.Bytes)
+	}
+
+	n.multipleOf = k.number("multipleOf")
+	if n.multipleOf != nil && n.multipleOf.Sign() <= 0 {
+		k.fail("multipleOf", "multipleOf must be greater than 0")
+	}
+	n.maximum = k.number("maximum")
+	n.exclusiveMaximum = k.number("exclusiveMaximum")
+	n.minimum = k.number("minimum")
+	n.exclusiveMinimum = k.number("exclusiveMinimum")
+
+	n.maxLength = k.count("maxLength")
+	n.minLength = k.count("minLength")
+	if pattern := k.value("pattern", gojson.JSONString, "a string"); pattern != nil {
+		n.pattern = k.regexp("pattern", string(pattern.Bytes))
+	}
+
+	n.maxItems = k.count("maxItems")
+	n.minItems = k.count("minItems")
+	if unique := k.value("uniqueItems", gojson.JSONBool, "a boolean"); unique != nil {
+		n.uniqueItems, _ = unique.ValueBool()
+	}
+	n.maxContains = k.count("maxContains")
+	n.minContains = k.count("minContains")
+
+	n.maxProperties = k.count("maxProperties")
+	n.minProperties = k.count("minProperties")
+	n.required = k.strings("required")
+	if dependent := k.value("dependentRequired", gojson.JSONObject, "an object"); dependent != nil {
+		for _, name := range dependent.Keys() {
+			value := dependent.Map[name]
+			if value.Type != gojson.JSONArray {
+				k.fail("dependentRequired", "dependentRequired must be an object of string arrays")
+				break
+			}
+			required := make([]string, len(value.Array))
+			for i, item := range value.Array {
+				if item.Type != gojson.JSONString {
+					k.fail("dependentRequired", "dependentRequired must be an object of string arrays")
+				}
+				required[i] = string(item.Bytes)
+			}
+			n.dependentRequired = append(n.dependentRequired, dependency{name: name, required: required})
+		}
+	}
+
+	n.allOf = k.schemaArray("allOf")
+	n.anyOf = k.schemaArray("anyOf")
+	n.oneOf = k.schemaArray("oneOf")
+	n.not = k.schema("not")
+	n.ifSchema = k.schema("if")
+	n.thenSchema = k.schema("then")
+	n.elseSchema = k.schema("else")
+	n.dependentSchemas = k.schemaMap("dependentSchemas")
+
+	n.prefixItems = k.schemaArray("prefixItems")
+	n.items = k.schema("items")
+	n.contains = k.schema("contains")
+	n.properties = k.schemaMap("properties")
+	for _, named := range k.schemaMap("patternProperties") {
+		n.patternProperties = append(n.patternProperties, patternNode{pattern: k.regexp("patternProperties", named.name), node: named.node})
+	}
+	n.additionalProperties = k.schema("additionalProperties")
+	n.propertyNames = k.schema("propertyNames")
+	n.unevaluatedItems = k.schema("unevaluatedItems")
+	n.unevaluatedProperties = k.schema("unevaluatedProperties")
+}
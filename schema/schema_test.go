@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/FanFani4/gojson"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	cases := []struct {
+		schema string
+		valid  []string
+		errors map[string]string // instance -> expected "instance pointer schema pointer" of every error
+	}{
+		{`{"type": "integer", "minimum": 1, "exclusiveMaximum": 10, "multipleOf": 0.5}`,
+			[]string{`1`, `2.0`, `9`},
+			map[string]string{`0`: ` /minimum`, `10`: ` /exclusiveMaximum`, `1.5`: ` /type`, `"1"`: ` /type`}},
+		{`{"type": ["string", "null"], "minLength": 2, "maxLength": 3, "pattern": "^a"}`,
+			[]string{`null`, `"ab"`, `"aéé"`},
+			map[string]string{`"a"`: ` /minLength`, `"abcd"`: ` /maxLength`, `"bb"`: ` /pattern`, `1`: ` /type`}},
+		{`{"enum": [1, "a", {"b": [null]}], "not": {"const": "a"}}`,
+			[]string{`1.0`, `{"b": [null]}`},
+			map[string]string{`"a"`: ` /not`, `2`: ` /enum`}},
+		{`{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}, "tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true}},
+			"additionalProperties": false, "dependentRequired": {"tags": ["id"]}, "minProperties": 1}`,
+			[]string{`{"id": 1}`, `{"id": 1, "tags": ["a", "b"]}`},
+			map[string]string{
+				`{"id": "x"}`:                      `/id /properties/id/type`,
+				`{"id": 1, "tags": ["a", 2, "a"]}`: `/tags /properties/tags/uniqueItems,/tags/1 /properties/tags/items/type`,
+				`{"tags": []}`:                     ` /required, /dependentRequired`,
+				`{"id": 1, "x~/": 2}`:              `/x~0~1 /additionalProperties`,
+				`{}`:                               ` /minProperties, /required`,
+			}},
+		{`{"prefixItems": [{"type": "integer"}, {"type": "string"}], "items": false, "contains": {"const": 1}, "maxContains": 1}`,
+			[]string{`[1, "a"]`, `[1]`},
+			map[string]string{`[1, 1]`: `/1 /prefixItems/1/type, /maxContains`, `[2]`: ` /contains`, `[1, "a", 3]`: `/2 /items`}},
+		{`{"anyOf": [{"type": "string"}, {"type": "integer"}], "oneOf": [{"minimum": 2}, {"maximum": 5}]}`,
+			[]string{`1`, `6`},
+			map[string]string{`3`: ` /oneOf`, `null`: ` /anyOf, /anyOf/0/type, /anyOf/1/type, /oneOf`}},
+		{`{"if": {"properties": {"kind": {"const": "a"}}}, "then": {"required": ["a"]}, "else": {"required": ["b"]}}`,
+			[]string{`{"kind": "a", "a": 1}`, `{"kind": "x", "b": 1}`, `{"a": 1}`},
+			map[string]string{`{"kind": "a"}`: ` /then/required`, `{"kind": "b"}`: ` /else/required`}},
+		{`{"$defs": {"node": {"type": "object", "properties": {"value": {"type": "number"}, "next": {"$ref": "#/$defs/node"}}}},
+			"$ref": "#/$defs/node"}`,
+			[]string{`{"value": 1, "next": {"value": 2, "next": {}}}`},
+			map[string]string{`{"next": {"next": {"value": "x"}}}`: `/next/next/value /$defs/node/properties/value/type`}},
+		{`{"$id": "https://example.com/root", "items": {"$ref": "item"}, "$defs": {"item": {"$id": "item", "$ref": "root#positive"},
+			"positive": {"$anchor": "positive", "exclusiveMinimum": 0}}}`,
+			[]string{`[1, 2]`},
+			map[string]string{`[1, 0]`: `/1 /$defs/positive/exclusiveMinimum`}},
+		{`{"properties": {"a": true}, "allOf": [{"properties": {"b": true}}], "anyOf": [{"properties": {"c": true}, "required": ["c"]}, true],
+			"unevaluatedProperties": false}`,
+			[]string{`{"a": 1, "b": 2}`, `{"a": 1, "c": 3}`},
+			map[string]string{`{"a": 1, "d": 4}`: `/d /unevaluatedProperties`, `{"c": 1, "anyOf": {"not": true}}`: `/anyOf /unevaluatedProperties`}},
+		{`{"prefixItems": [true], "if": {"contains": {"type": "string"}}, "then": true, "unevaluatedItems": {"type": "null"}}`,
+			[]string{`[1, "a", null]`, `[1, null]`},
+			map[string]string{`[1, 2]`: `/1 /unevaluatedItems/type`}},
+		{`{"propertyNames": {"maxLength": 2}, "dependentSchemas": {"a": {"required": ["b"]}}}`,
+			[]string{`{"b": 1}`, `{"a": 1, "b": 2}`},
+			map[string]string{`{"abc": 1}`: `/abc /propertyNames/maxLength`, `{"a": 1}`: ` /dependentSchemas/a/required`}},
+		{`{"properties": {"d": {"format": "date-time"}, "e": {"format": "email"}, "i": {"format": "ipv4"}, "u": {"format": "uuid"},
+			"h": {"format": "hostname"}, "p": {"format": "json-pointer"}, "x": {"format": "custom"}}}`,
+			[]string{`{"d": "2024-02-29T10:00:00.5+02:00", "e": "a@b.com", "i": "10.0.0.1", "u": "123e4567-e89b-12d3-a456-426614174000", "h": "a-b.com", "p": "/a~1b/0", "x": "?"}`},
+			map[string]string{
+				`{"d": "2023-02-29T10:00:00Z"}`:  `/d /properties/d/format`,
+				`{"e": "a@"}`:                    `/e /properties/e/format`,
+				`{"p": "/a~2"}`:                  `/p /properties/p/format`,
+				`{"i": "10.0.0.256", "h": "-a"}`: `/i /properties/i/format,/h /properties/h/format`,
+			}},
+		{`false`, nil, map[string]string{`1`: ` `}},
+		{`{"$ref": "#"}`, nil, map[string]string{`1`: ` /$ref`}},
+		{`{"$defs": {"a": {"allOf": [{"$ref": "#/$defs/b"}]}, "b": {"$ref": "#/$defs/a"}}, "items": {"$ref": "#/$defs/a"}}`,
+			[]string{`[]`}, map[string]string{`[1]`: `/0 /$defs/b/$ref`}},
+		// extensible schema through $dynamicRef
+		{`{"$id": "https://example.com/strict", "$ref": "tree", "unevaluatedProperties": false,
+			"$defs": {"tree": {"$id": "tree", "$dynamicAnchor": "node", "type": "object",
+				"properties": {"children": {"type": "array", "items": {"$dynamicRef": "#node"}}}},
+			"node": {"$dynamicAnchor": "node", "$ref": "tree", "unevaluatedProperties": false}}}`,
+			[]string{`{"children": [{"children": []}]}`},
+			map[string]string{`{"children": [{"x": 1}]}`: `/children/0/x /$defs/node/unevaluatedProperties,/children /unevaluatedProperties`}},
+	}
+	for _, c := range cases {
+		s, err := Compile(gojson.Unmarshal([]byte(c.schema)))
+		if err != nil {
+			t.Fatal(c.schema, err)
+		}
+		for _, instance := range c.valid {
+			if errs := s.Validate(gojson.Unmarshal([]byte(instance))); errs != nil {
+				t.Fatalf("%s: %s is expected to be valid, got %v", c.schema, instance, errs)
+			}
+		}
+		for instance, expected := range c.errors {
+			errs := s.Validate(gojson.Unmarshal([]byte(instance)))
+			pointers := make([]string, len(errs))
+			for i, e := range errs {
+				pointers[i] = e.InstancePointer + " " + e.SchemaPointer
+			}
+			if got := strings.Join(pointers, ","); got != expected {
+				t.Fatalf("%s: %s: expected errors %s, got %s %v", c.schema, instance, expected, got, errs)
+			}
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	invalid := map[string]string{
+		`1`:                ``,
+		`{"type": "text"}`: `/type`,
+		`{"properties": {"a": {"minimum": "1"}}}`: `/properties/a/minimum`,
+		`{"allOf": []}`:                                            `/allOf`,
+		`{"items": {"pattern": "("}}`:                              `/items/pattern`,
+		`{"$ref": "#/$defs/missing"}`:                              `/$ref`,
+		`{"$ref": "https://example.com/other"}`:                    `/$ref`,
+		`{"anyOf": [{"$ref": "#nowhere"}]}`:                        `/anyOf/0/$ref`,
+		`{"maxLength": -1}`:                                        `/maxLength`,
+		`{"multipleOf": 0}`:                                        `/multipleOf`,
+		`{"$defs": {"a": {"required": [1]}}, "$ref": "#/$defs/a"}`: `/$defs/a/required`,
+	}
+	for schema, pointer := range invalid {
+		_, err := Compile(gojson.Unmarshal([]byte(schema)))
+		compileErr, ok := err.(*CompileError)
+		if !ok || compileErr.Pointer != pointer {
+			t.Fatalf("%s: expected error at %q, got %v", schema, pointer, err)
+		}
+	}
+
+	s := MustCompile(gojson.Unmarshal([]byte(`{"properties": {"a": {"type": "string"}}}`)))
+	errs := s.Validate(gojson.Unmarshal([]byte(`{"a": 1}`)))
+	if len(errs) != 1 || errs[0].Keyword != "type" || errs[0].Error() != `expected string, got number at "/a" (schema "/properties/a/type")` {
+		t.Fatal("unexpected errors", errs)
+	}
+	if !s.IsValid(gojson.Unmarshal([]byte(`{"a": "x"}`))) {
+		t.Fatal("expected valid document")
+	}
+}
@@ -0,0 +1,497 @@
+package schema
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/FanFani4/gojson"
+	"github.com/FanFani4/gojson/internal/format"
+)
+
+// ValidationError is one violation of the schema
+type ValidationError struct {
+	InstancePointer string // JSON Pointer of the invalid value in the document
+	SchemaPointer   string // JSON Pointer of the failed keyword in the schema
+	Keyword         string // failed keyword, empty for false schema
+	Message         string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s at %q (schema %q)", e.Message, e.InstancePointer, e.SchemaPointer)
+}
+
+// Validate validates the document and returns all violations, nil if the document is valid
+// anyOf and oneOf report their own error followed by errors of every subschema
+func (s *Schema) Validate(instance *gojson.GoJSON) []ValidationError {
+	v := validator{}
+	return v.validate(s.root, instance, nil).errors
+}
+
+// IsValid reports whether the document is valid
+func (s *Schema) IsValid(instance *gojson.GoJSON) bool {
+	return len(s.Validate(instance)) == 0
+}
+
+// validator holds dynamic scope, resources entered during evaluation from the outermost one,
+// and schemas being applied to instances to detect references looping without consuming the instance
+type validator struct {
+	scope  []*node
+	active map[visit]bool
+}
+
+// visit is schema applied to instance
+type visit struct {
+	n        *node
+	instance *gojson.GoJSON
+}
+
+// result of validating one instance against one schema, evaluated properties and items
+// are annotations for unevaluatedProperties and unevaluatedItems
+type result struct {
+	errors []ValidationError
+	props  map[string]bool
+	items  map[int]bool
+}
+
+func (r *result) valid() bool {
+	return len(r.errors) == 0
+}
+
+func (r *result) fail(n *node, keyword string, path []string, format string, args ...interface{}) {
+	pointer := n.pointer
+	if keyword != "" {
+		pointer += "/" + keyword
+	}
+	r.errors = append(r.errors, ValidationError{
+		InstancePointer: gojson.FormatPointer(path...),
+		SchemaPointer:   pointer,
+		Keyword:         keyword,
+		Message:         fmt.Sprintf(format, args...),
+	})
+}
+
+// apply adds result of subschema applied to the same instance, annotations of failed subschemas are dropped
+func (r *result) apply(sub *result) {
+	if !sub.valid() {
+		r.errors = append(r.errors, sub.errors...)
+		return
+	}
+	for name := range sub.props {
+		r.evaluatedProperty(name)
+	}
+	for i := range sub.items {
+		r.evaluatedItem(i)
+	}
+}
+
+func (r *result) evaluatedProperty(name string) {
+	if r.props == nil {
+		r.props = make(map[string]bool)
+	}
+	r.props[name] = true
+}
+
+func (r *result) evaluatedItem(i int) {
+	if r.items == nil {
+		r.items = make(map[int]bool)
+	}
+	r.items[i] = true
+}
+
+func appendToken(path []string, token string) []string {
+	return append(path[:len(path):len(path)], token)
+}
+
+func (v *validator) validate(n *node, instance *gojson.GoJSON, path []string) *result {
+	r := &result{}
+	if n.boolean != nil {
+		if !*n.boolean {
+			r.fail(n, "", path, "no value is allowed")
+		}
+		return r
+	}
+	if v.active == nil {
+		v.active = make(map[visit]bool)
+	}
+	key := visit{n: n, instance: instance}
+	v.active[key] = true
+	defer delete(v.active, key)
+	if n.anchors != nil {
+		v.scope = append(v.scope, n)
+		defer func() { v.scope = v.scope[:len(v.scope)-1] }()
+	}
+	if n.ref != nil {
+		r.apply(v.follow(n, "$ref", n.ref, instance, path))
+	}
+	if n.dynamicRef != nil {
+		target := n.dynamicRef
+		if n.dynamic != "" {
+			for _, resource := range v.scope {
+				if anchor, found := resource.anchors[n.dynamic]; found {
+					target = anchor
+					break
+				}
+			}
+		}
+		r.apply(v.follow(n, "$dynamicRef", target, instance, path))
+	}
+
+	v.validateValue(n, instance, path, r)
+	switch instance.Type {
+	case gojson.JSONInt, gojson.JSONFloat:
+		v.validateNumber(n, instance, path, r)
+	case gojson.JSONString:
+		v.validateString(n, instance, path, r)
+	case gojson.JSONArray:
+		v.validateArray(n, instance, path, r)
+	case gojson.JSONObject:
+		v.validateObject(n, instance, path, r)
+	}
+	v.validateApplicators(n, instance, path, r)
+
+	if n.unevaluatedItems != nil && instance.Type == gojson.JSONArray {
+		for i, item := range instance.Array {
+			if !r.items[i] {
+				r.errors = append(r.errors, v.validate(n.unevaluatedItems, item, appendToken(path, strconv.Itoa(i))).errors...)
+			}
+		}
+		for i := range instance.Array {
+			r.evaluatedItem(i)
+		}
+	}
+	if n.unevaluatedProperties != nil && instance.Type == gojson.JSONObject {
+		for _, name := range instance.Keys() {
+			if !r.props[name] {
+				r.errors = append(r.errors, v.validate(n.unevaluatedProperties, instance.Map[name], appendToken(path, name)).errors...)
+			}
+			r.evaluatedProperty(name)
+		}
+	}
+	return r
+}
+
+// follow applies referenced schema, a reference back to schema already applied to the same instance
+// would recurse forever and is reported as an error of the keyword
+func (v *validator) follow(n *node, keyword string, target *node, instance *gojson.GoJSON, path []string) *result {
+	if v.active[visit{n: target, instance: instance}] {
+		r := &result{}
+		r.fail(n, keyword, path, "reference cycle doesn't consume the instance")
+		return r
+	}
+	return v.validate(target, instance, path)
+}
+
+// validateValue checks type, enum and const
+func (v *validator) validateValue(n *node, instance *gojson.GoJSON, path []string, r *result) {
+	if len(n.types) > 0 {
+		matches := false
+		for _, typ := range n.types {
+			if hasType(instance, typ) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			if len(n.types) == 1 {
+				r.fail(n, "type", path, "expected %s, got %s", n.types[0], typeName(instance))
+			} else {
+				r.fail(n, "type", path, "expected one of %v, got %s", n.types, typeName(instance))
+			}
+		}
+	}
+	if n.enum != nil {
+		matches := false
+		for _, value := range n.enum {
+			if equal(instance, value) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			r.fail(n, "enum", path, "value must be one of the enum values")
+		}
+	}
+	if n.constant != nil && !equal(instance, n.constant) {
+		r.fail(n, "const", path, "value must be %s", n.constant.Marshal())
+	}
+}
+
+func (v *validator) validateNumber(n *node, instance *gojson.GoJSON, path []string, r *result) {
+	if n.multipleOf != nil && !isMultiple(instance.Bytes, n.multipleOf) {
+		r.fail(n, "multipleOf", path, "%s is not a multiple of %s", instance.Bytes, n.multipleOf.RatString())
+	}
+	if n.maximum != nil && compareNumber(instance.Bytes, n.maximum) > 0 {
+		r.fail(n, "maximum", path, "%s is greater than %s", instance.Bytes, n.maximum.RatString())
+	}
+	if n.exclusiveMaximum != nil && compareNumber(instance.Bytes, n.exclusiveMaximum) >= 0 {
+		r.fail(n, "exclusiveMaximum", path, "%s is not less than %s", instance.Bytes, n.exclusiveMaximum.RatString())
+	}
+	if n.minimum != nil && compareNumber(instance.Bytes, n.minimum) < 0 {
+		r.fail(n, "minimum", path, "%s is less than %s", instance.Bytes, n.minimum.RatString())
+	}
+	if n.exclusiveMinimum != nil && compareNumber(instance.Bytes, n.exclusiveMinimum) <= 0 {
+		r.fail(n, "exclusiveMinimum", path, "%s is not greater than %s", instance.Bytes, n.exclusiveMinimum.RatString())
+	}
+}
+
+func (v *validator) validateString(n *node, instance *gojson.GoJSON, path []string, r *result) {
+	if n.maxLength >= 0 || n.minLength >= 0 {
+		length := utf8.RuneCount(instance.Bytes)
+		if n.maxLength >= 0 && length > n.maxLength {
+			r.fail(n, "maxLength", path, "string is longer than %d characters", n.maxLength)
+		}
+		if n.minLength >= 0 && length < n.minLength {
+			r.fail(n, "minLength", path, "string is shorter than %d characters", n.minLength)
+		}
+	}
+	if n.pattern != nil && !n.pattern.Match(instance.Bytes) {
+		r.fail(n, "pattern", path, "string doesn't match pattern %q", n.pattern.String())
+	}
+	if n.format != "" {
+		if valid, _ := format.Check(n.format, string(instance.Bytes)); !valid {
+			r.fail(n, "format", path, "string is not valid %s", n.format)
+		}
+	}
+}
+
+func (v *validator) validateArray(n *node, instance *gojson.GoJSON, path []string, r *result) {
+	items := instance.Array
+	if n.maxItems >= 0 && len(items) > n.maxItems {
+		r.fail(n, "maxItems", path, "array has more than %d items", n.maxItems)
+	}
+	if n.minItems >= 0 && len(items) < n.minItems {
+		r.fail(n, "minItems", path, "array has less than %d items", n.minItems)
+	}
+	if n.uniqueItems {
+		seen := make(map[[32]byte][]int, len(items))
+	unique:
+		for i, item := range items {
+			hash := item.Hash()
+			for _, j := range seen[hash] {
+				if equal(item, items[j]) {
+					r.fail(n, "uniqueItems", path, "items %d and %d are equal", j, i)
+					break unique
+				}
+			}
+			seen[hash] = append(seen[hash], i)
+		}
+	}
+	for i, item := range items {
+		var sub *node
+		switch {
+		case i < len(n.prefixItems):
+			sub = n.prefixItems[i]
+		case n.items != nil:
+			sub = n.items
+		default:
+			continue
+		}
+		r.errors = append(r.errors, v.validate(sub, item, appendToken(path, strconv.Itoa(i))).errors...)
+		r.evaluatedItem(i)
+	}
+	if n.contains != nil {
+		matched := 0
+		for i, item := range items {
+			if v.validate(n.contains, item, appendToken(path, strconv.Itoa(i))).valid() {
+				matched++
+				r.evaluatedItem(i)
+			}
+		}
+		minimum := 1
+		if n.minContains >= 0 {
+			minimum = n.minContains
+		}
+		if matched < minimum {
+			keyword := "contains"
+			if n.minContains >= 0 {
+				keyword = "minContains"
+			}
+			r.fail(n, keyword, path, "array has less than %d items matching contains schema", minimum)
+		}
+		if n.maxContains >= 0 && matched > n.maxContains {
+			r.fail(n, "maxContains", path, "array has more than %d items matching contains schema", n.maxContains)
+		}
+	}
+}
+
+func (v *validator) validateObject(n *node, instance *gojson.GoJSON, path []string, r *result) {
+	if n.maxProperties >= 0 && len(instance.Map) > n.maxProperties {
+		r.fail(n, "maxProperties", path, "object has more than %d properties", n.maxProperties)
+	}
+	if n.minProperties >= 0 && len(instance.Map) < n.minProperties {
+		r.fail(n, "minProperties", path, "object has less than %d properties", n.minProperties)
+	}
+	for _, name := range n.required {
+		if _, found := instance.Map[name]; !found {
+			r.fail(n, "required", path, "missing required property %q", name)
+		}
+	}
+	for _, dependency := range n.dependentRequired {
+		if _, found := instance.Map[dependency.name]; !found {
+			continue
+		}
+		for _, name := range dependency.required {
+			if _, found := instance.Map[name]; !found {
+				r.fail(n, "dependentRequired", path, "property %q is required by %q", name, dependency.name)
+			}
+		}
+	}
+	for _, dependent := range n.dependentSchemas {
+		if _, found := instance.Map[dependent.name]; found {
+			r.apply(v.validate(dependent.node, instance, path))
+		}
+	}
+
+	names := instance.Keys()
+	matched := make(map[string]bool, len(names))
+	for _, property := range n.properties {
+		if value, found := instance.Map[property.name]; found {
+			r.errors = append(r.errors, v.validate(property.node, value, appendToken(path, property.name)).errors...)
+			matched[property.name] = true
+			r.evaluatedProperty(property.name)
+		}
+	}
+	for _, property := range n.patternProperties {
+		for _, name := range names {
+			if property.pattern.MatchString(name) {
+				r.errors = append(r.errors, v.validate(property.node, instance.Map[name], appendToken(path, name)).errors...)
+				matched[name] = true
+				r.evaluatedProperty(name)
+			}
+		}
+	}
+	if n.additionalProperties != nil {
+		for _, name := range names {
+			if !matched[name] {
+				r.errors = append(r.errors, v.validate(n.additionalProperties, instance.Map[name], appendToken(path, name)).errors...)
+				r.evaluatedProperty(name)
+			}
+		}
+	}
+	if n.propertyNames != nil {
+		for _, name := range names {
+			key := &gojson.GoJSON{Type: gojson.JSONString, Bytes: []byte(name)}
+			r.errors = append(r.errors, v.validate(n.propertyNames, key, appendToken(path, name)).errors...)
+		}
+	}
+}
+
+// validateApplicators applies in-place applicators, their annotations are collected from valid subschemas only
+func (v *validator) validateApplicators(n *node, instance *gojson.GoJSON, path []string, r *result) {
+	for _, sub := range n.allOf {
+		r.apply(v.validate(sub, instance, path))
+	}
+	if n.anyOf != nil {
+		var failed []ValidationError
+		valid := false
+		for _, sub := range n.anyOf {
+			subResult := v.validate(sub, instance, path)
+			if subResult.valid() {
+				valid = true
+				r.apply(subResult)
+			} else {
+				failed = append(failed, subResult.errors...)
+			}
+		}
+		if !valid {
+			r.fail(n, "anyOf", path, "value doesn't match any schema")
+			r.errors = append(r.errors, failed...)
+		}
+	}
+	if n.oneOf != nil {
+		var failed []ValidationError
+		var matched []int
+		for i, sub := range n.oneOf {
+			subResult := v.validate(sub, instance, path)
+			if subResult.valid() {
+				matched = append(matched, i)
+				if len(matched) == 1 {
+					r.apply(subResult)
+				}
+			} else {
+				failed = append(failed, subResult.errors...)
+			}
+		}
+		switch len(matched) {
+		case 0:
+			r.fail(n, "oneOf", path, "value doesn't match any schema")
+			r.errors = append(r.errors, failed...)
+		case 1:
+		default:
+			r.fail(n, "oneOf", path, "value matches schemas %v, only one is allowed", matched)
+		}
+	}
+	if n.not != nil && v.validate(n.not, instance, path).valid() {
+		r.fail(n, "not", path, "value must not match the schema")
+	}
+	if n.ifSchema != nil {
+		if ifResult := v.validate(n.ifSchema, instance, path); ifResult.valid() {
+			r.apply(ifResult)
+			if n.thenSchema != nil {
+				r.apply(v.validate(n.thenSchema, instance, path))
+			}
+		} else if n.elseSchema != nil {
+			r.apply(v.validate(n.elseSchema, instance, path))
+		}
+	}
+}
+
+// hasType reports whether instance is of JSON Schema type, integers are numbers without fractional part
+func hasType(instance *gojson.GoJSON, typ string) bool {
+	switch typ {
+	case "integer":
+		return instance.Type == gojson.JSONInt || instance.Type == gojson.JSONFloat && isMultiple(instance.Bytes, big.NewRat(1, 1))
+	case "number":
+		return instance.Type == gojson.JSONInt || instance.Type == gojson.JSONFloat
+	}
+	return typeName(instance) == typ
+}
+
+func typeName(instance *gojson.GoJSON) string {
+	switch instance.Type {
+	case gojson.JSONNull:
+		return "null"
+	case gojson.JSONBool:
+		return "boolean"
+	case gojson.JSONInt, gojson.JSONFloat:
+		return "number"
+	case gojson.JSONString:
+		return "string"
+	case gojson.JSONArray:
+		return "array"
+	case gojson.JSONObject:
+		return "object"
+	}
+	return "invalid"
+}
+
+// equal compares values for enum, const and uniqueItems, 1 and 1.0 are equal
+func equal(a, b *gojson.GoJSON) bool {
+	return gojson.Equal(a, b, gojson.EqualOptions{NumbersByValue: true})
+}
+
+// compareNumber compares json number with limit, numbers too large for big.Rat are compared as big.Float
+func compareNumber(number []byte, limit *big.Rat) int {
+	if r, err := gojson.Number(number).Rat(); err == nil {
+		return r.Cmp(limit)
+	}
+	f, err := gojson.Number(number).BigFloat()
+	if err != nil {
+		return 0
+	}
+	return f.Cmp(new(big.Float).SetRat(limit))
+}
+
+// isMultiple reports whether json number is an integer multiple of divisor
+func isMultiple(number []byte, divisor *big.Rat) bool {
+	if r, err := gojson.Number(number).Rat(); err == nil {
+		return new(big.Rat).Quo(r, divisor).IsInt()
+	}
+	f, err := gojson.Number(number).BigFloat()
+	if err != nil {
+		return false
+	}
+	return new(big.Float).Quo(f, new(big.Float).SetRat(divisor)).IsInt()
+}
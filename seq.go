@@ -0,0 +1,191 @@
+package gojson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// recordSeparator starts every record of RFC 7464 json text sequence
+const recordSeparator byte = 0x1E
+
+// errOutsideRecord is reported for data which doesn't follow a record separator
+var errOutsideRecord = errors.New("data outside of a record")
+
+// errNoSpace is reported for top-level number or literal not followed by whitespace
+var errNoSpace = errors.New("number or literal must be followed by whitespace")
+
+// SeqError reports malformed record of json text sequence
+type SeqError struct {
+	Record    int  // record number, starting from 1
+	Truncated bool // record ended before its value was complete
+	Err       error
+}
+
+func (e *SeqError) Error() string {
+	if e.Truncated {
+		return fmt.Sprintf("record %d: truncated: %v", e.Record, e.Err)
+	}
+	return fmt.Sprintf("record %d: %v", e.Record, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *SeqError) Unwrap() error {
+	return e.Err
+}
+
+// SeqReader reads RFC 7464 json text sequences (application/json-seq)
+// every record starts with RS (0x1E) and ends with LF, a record is returned as soon as its value is complete
+type SeqReader struct {
+	dec     Decoder
+	record  int
+	discard bool // rest of the current record is malformed and was already reported
+}
+
+// NewSeqReader returns a reader of json text sequence from r, opts are the same as for Parse
+func NewSeqReader(r io.Reader, opts ...ParseOptions) *SeqReader {
+	return &SeqReader{dec: *NewDecoder(r, opts...)}
+}
+
+// Read returns json of the next record, io.EOF is returned at the end of input
+// malformed records are returned as *SeqError and skipped as RFC 7464 requires,
+// so reading can continue with the next record
+// top-level numbers and literals must be followed by whitespace, otherwise they may be truncated
+func (sr *SeqReader) Read() (*GoJSON, error) {
+	dec := &sr.dec
+	if err := sr.seekRecord(); err != nil {
+		return nil, err
+	}
+
+	// skip whitespace, consecutive separators don't denote empty records
+	for {
+		data := dec.buf[dec.off:]
+		i := 0
+		for i < len(data) && isSeqSpace(data[i]) {
+			i++
+		}
+		dec.advance(i)
+		if dec.off < len(dec.buf) {
+			break
+		}
+		if dec.err != nil {
+			return nil, dec.err
+		}
+		dec.fill()
+	}
+
+	var s valueScanner
+	for {
+		data := dec.buf[dec.off:]
+		next := bytes.IndexByte(data, recordSeparator)
+		limit := data
+		if next >= 0 {
+			limit = data[:next]
+		}
+		if end, done := s.scan(limit); done {
+			// a record starting with a delimiter is a single byte, the parser reports it
+			if isScalarStart(data[0]) && end < len(data) && !isSeqSpace(data[end]) {
+				sr.discard = true
+				return nil, &SeqError{Record: sr.record, Err: errNoSpace}
+			}
+			json, err := dec.parse(end)
+			if err != nil {
+				sr.discard = true
+				return nil, &SeqError{Record: sr.record, Err: err}
+			}
+			return json, nil
+		}
+		if next >= 0 || dec.err == io.EOF {
+			return nil, sr.truncated(len(limit))
+		}
+		if dec.err != nil {
+			return nil, dec.err
+		}
+		dec.fill()
+	}
+}
+
+// seekRecord consumes input up to and including the next record separator
+// anything but whitespace before it is reported unless it belongs to already reported record
+func (sr *SeqReader) seekRecord() error {
+	dec := &sr.dec
+	outside := false
+	defer func() {
+		sr.discard = false
+	}()
+	for {
+		data := dec.buf[dec.off:]
+		i := bytes.IndexByte(data, recordSeparator)
+		if i < 0 {
+			i = len(data)
+		}
+		if !sr.discard && len(skip(data[:i])) > 0 {
+			outside = true
+		}
+		if i < len(data) && outside {
+			// separator is left for the next call
+			dec.advance(i)
+			return &SeqError{Record: sr.record, Err: errOutsideRecord}
+		}
+		if i < len(data) {
+			dec.advance(i + 1)
+			sr.record++
+			return nil
+		}
+		dec.advance(i)
+		if dec.err != nil {
+			if outside {
+				return &SeqError{Record: sr.record, Err: errOutsideRecord}
+			}
+			return dec.err
+		}
+		dec.fill()
+	}
+}
+
+// truncated reports record which value isn't complete, n bytes of it are consumed
+func (sr *SeqReader) truncated(n int) error {
+	_, err := sr.dec.parse(n)
+	if err == nil {
+		// top-level number or literal cut by the end of the record
+		err = errNoSpace
+	}
+	return &SeqError{Record: sr.record, Truncated: true, Err: err}
+}
+
+// isSeqSpace reports whether c is whitespace or record separator
+func isSeqSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == recordSeparator
+}
+
+// isScalarStart reports whether value starting with c is a number or literal
+func isScalarStart(c byte) bool {
+	return c != startObject && c != startArray && c != startString
+}
+
+// SeqWriter writes RFC 7464 json text sequences
+type SeqWriter struct {
+	w    io.Writer
+	opts MarshalOptions
+	buf  bytes.Buffer
+}
+
+// NewSeqWriter returns a writer of json text sequence to w, opts are the same as for MarshalWith
+func NewSeqWriter(w io.Writer, opts ...MarshalOptions) *SeqWriter {
+	sw := &SeqWriter{w: w}
+	if len(opts) > 0 {
+		sw.opts = opts[0]
+	}
+	return sw
+}
+
+// Write writes record separator, json of g and a newline
+func (sw *SeqWriter) Write(g *GoJSON) error {
+	sw.buf.Reset()
+	sw.buf.WriteByte(recordSeparator)
+	g.MarshalWith(sw.opts, &sw.buf)
+	sw.buf.WriteByte('\n')
+	_, err := sw.w.Write(sw.buf.Bytes())
+	return err
+}
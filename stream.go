@@ -0,0 +1,223 @@
+package gojson
+
+import (
+	"bufio"
+	"io"
+)
+
+// encoderBufferSize is the amount of output Encoder keeps in memory before writing it out
+const encoderBufferSize = 4096
+
+// Encoder writes json values to an output stream
+type Encoder struct {
+	w    *bufio.Writer
+	opts MarshalOptions
+}
+
+// NewEncoder returns an encoder that writes to w through a buffer of bounded size
+// opts are the same as for MarshalWith
+func NewEncoder(w io.Writer, opts ...MarshalOptions) *Encoder {
+	enc := &Encoder{w: bufio.NewWriterSize(w, encoderBufferSize)}
+	if len(opts) > 0 {
+		enc.opts = opts[0]
+	}
+	return enc
+}
+
+// Encode writes json of g followed by a newline
+// output is flushed before Encode returns, the first write error is returned by this and every later call
+func (enc *Encoder) Encode(g *GoJSON) error {
+	e := encoder{opts: enc.opts, bf: enc.w}
+	e.encode(g)
+	enc.w.WriteByte('\n')
+	return enc.w.Flush()
+}
+
+// decoderBufferSize is the initial size of Decoder buffer, it grows to fit the largest value
+const decoderBufferSize = 4096
+
+// Decoder reads json values one by one from an input stream
+type Decoder struct {
+	r    io.Reader
+	opts ParseOptions
+	buf  []byte
+	off  int   // start of unread data in buf
+	err  error // error returned by the last read
+	pos  int   // stream offset of buf[off]
+	line int   // line of buf[off]
+	col  int   // column of buf[off]
+}
+
+// NewDecoder returns a decoder that reads from r, opts are the same as for Parse
+func NewDecoder(r io.Reader, opts ...ParseOptions) *Decoder {
+	dec := &Decoder{r: r, line: 1, col: 1}
+	if len(opts) > 0 {
+		dec.opts = opts[0]
+	}
+	return dec
+}
+
+// Decode reads the next json value, values may be concatenated or separated by whitespace
+// it returns io.EOF when there are no more values, malformed values are reported as *SyntaxError
+// with position in the whole stream and are skipped so decoding can continue
+func (dec *Decoder) Decode() (*GoJSON, error) {
+	for {
+		dec.skipSpace()
+		if dec.off < len(dec.buf) {
+			break
+		}
+		if dec.err != nil {
+			return nil, dec.err
+		}
+		dec.fill()
+	}
+
+	var s valueScanner
+	for {
+		if end, done := s.scan(dec.buf[dec.off:]); done {
+			return dec.parse(end)
+		}
+		if dec.err == io.EOF {
+			// value is incomplete, let parser describe what is missing
+			return dec.parse(len(dec.buf) - dec.off)
+		}
+		if dec.err != nil {
+			return nil, dec.err
+		}
+		dec.fill()
+	}
+}
+
+// parse parses and consumes n bytes of unread data
+func (dec *Decoder) parse(n int) (*GoJSON, error) {
+	// tree references input bytes, so they must not be shared with the buffer
+	value := append([]byte(nil), dec.buf[dec.off:dec.off+n]...)
+	pos, line, col := dec.pos, dec.line, dec.col
+	dec.advance(n)
+	json, err := parseWhole(value, dec.opts)
+	if syntaxErr, ok := err.(*SyntaxError); ok {
+		syntaxErr.Offset += pos
+		if syntaxErr.Line == 1 {
+			syntaxErr.Column += col - 1
+		}
+		syntaxErr.Line += line - 1
+	}
+	return json, err
+}
+
+func (dec *Decoder) skipSpace() {
+	n := len(skip(dec.buf[dec.off:]))
+	dec.advance(len(dec.buf) - dec.off - n)
+}
+
+// advance consumes n bytes of unread data keeping track of the position
+func (dec *Decoder) advance(n int) {
+	for _, c := range dec.buf[dec.off : dec.off+n] {
+		dec.col++
+		if c == '\n' {
+			dec.line++
+			dec.col = 1
+		}
+	}
+	dec.off += n
+	dec.pos += n
+}
+
+// fill reads more data into the buffer
+func (dec *Decoder) fill() {
+	if dec.off > 0 {
+		n := copy(dec.buf, dec.buf[dec.off:])
+		dec.buf = dec.buf[:n]
+		dec.off = 0
+	}
+	if len(dec.buf) == cap(dec.buf) {
+		buf := make([]byte, len(dec.buf), 2*cap(dec.buf)+decoderBufferSize)
+		copy(buf, dec.buf)
+		dec.buf = buf
+	}
+	n, err := dec.r.Read(dec.buf[len(dec.buf):cap(dec.buf)])
+	dec.buf = dec.buf[:len(dec.buf)+n]
+	if err != nil {
+		dec.err = err
+	}
+}
+
+// valueScanner finds the end of a json value, it keeps state between calls
+// so a value split between reads is scanned only once
+type valueScanner struct {
+	pos      int
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+// scan continues scanning data which starts with the value
+// it returns length of the value and true once the end is found
+// the value itself is not validated, that's the job of the parser
+func (s *valueScanner) scan(data []byte) (int, bool) {
+	if s.pos == 0 && len(data) > 0 {
+		switch data[0] {
+		case startObject, startArray:
+			s.depth++
+		case startString:
+			s.inString = true
+		default:
+			return s.scanScalar(data)
+		}
+		s.pos++
+	}
+	if s.depth == 0 && !s.inString {
+		return s.scanScalar(data)
+	}
+	for ; s.pos < len(data); s.pos++ {
+		c := data[s.pos]
+		switch {
+		case s.escaped:
+			s.escaped = false
+		case s.inString:
+			if c == escape {
+				s.escaped = true
+			} else if c == startString {
+				s.inString = false
+				if s.depth == 0 {
+					return s.pos + 1, true
+				}
+			}
+		case c == startString:
+			s.inString = true
+		case c == startObject || c == startArray:
+			s.depth++
+		case c == stopObject || c == stopArray:
+			s.depth--
+			if s.depth == 0 {
+				return s.pos + 1, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// scanScalar scans number or literal up to the next delimiter,
+// a value that starts with a delimiter is taken as one byte so the parser can report it
+func (s *valueScanner) scanScalar(data []byte) (int, bool) {
+	if s.pos == 0 {
+		if isDelimiter(data[0]) {
+			return 1, true
+		}
+		s.pos++
+	}
+	for ; s.pos < len(data); s.pos++ {
+		if isDelimiter(data[s.pos]) {
+			return s.pos, true
+		}
+	}
+	return 0, false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case ',', ':', startArray, stopArray, startObject, stopObject, startString:
+		return true
+	}
+	return c <= 32
+}
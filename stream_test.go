@@ -0,0 +1,192 @@
+package gojson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		w.limit = 0
+		return 0, errors.New("disk full")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestEncoder(t *testing.T) {
+	json := Unmarshal(data)
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Encode(json); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(json.Get("person").Get("name")); err != nil {
+		t.Fatal(err)
+	}
+	expected := string(json.Marshal()) + "\n" + string(json.Get("person").Get("name").Marshal()) + "\n"
+	if buf.String() != expected {
+		t.Fatal("wrong output", buf.String())
+	}
+
+	buf.Reset()
+	enc = NewEncoder(&buf, MarshalOptions{Indent: "  ", ColonSpace: true, ASCIIOnly: true})
+	if err := enc.Encode(Unmarshal([]byte(`{"a": "é"}`))); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "{\n  \"a\": \"\\u00e9\"\n}\n" {
+		t.Fatal("wrong output", buf.String())
+	}
+
+	big := NewArray()
+	for i := 0; i < 2000; i++ {
+		big.SetString(-1, "some long enough string value")
+	}
+	if err := NewEncoder(&failingWriter{limit: 10000}).Encode(big); err == nil {
+		t.Fatal("expected write error")
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	input := " {\"a\": [1, \"x\\\"]\"]}{\"b\":{}}\n[]\"s\" 12 -3.5e2 true null\n\"\\u00e9\" [1,\n 2 x] 7"
+	expected := []string{`{"a":[1,"x\"]"]}`, `{"b":{}}`, `[]`, `"s"`, `12`, `-3.5e2`, `true`, `null`, `"é"`}
+	dec := NewDecoder(iotest.OneByteReader(strings.NewReader(input)))
+	for _, e := range expected {
+		json, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out := string(json.Marshal()); out != e {
+			t.Fatalf("expected %s, got %s", e, out)
+		}
+	}
+	_, err := dec.Decode()
+	syntaxErr, ok := err.(*SyntaxError)
+	if !ok || syntaxErr.Line != 4 || syntaxErr.Column != 4 || syntaxErr.Offset != 70 {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if json, err := dec.Decode(); err != nil || string(json.Bytes) != "7" {
+		t.Fatal("expected 7", err)
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Fatal("expected EOF", err)
+	}
+
+	dec = NewDecoder(strings.NewReader(`truefalse [1, 2`))
+	if _, err := dec.Decode(); err == nil {
+		t.Fatal("expected error for concatenated literals")
+	}
+	if _, err := dec.Decode(); err == nil || err == io.EOF {
+		t.Fatal("expected error for truncated array", err)
+	}
+}
+
+func TestLineReader(t *testing.T) {
+	input := "{\"a\": 1}\r\n\n[1, 2]\n{\"broken\": \n\"last\""
+	lr := NewLineReader(strings.NewReader(input))
+	if json, err := lr.Read(); err != nil || string(json.Marshal()) != `{"a":1}` {
+		t.Fatal("wrong first line", err)
+	}
+	if json, err := lr.Read(); err != nil || string(json.Marshal()) != `[1,2]` || lr.Line() != 3 {
+		t.Fatal("wrong third line", err)
+	}
+	_, err := lr.Read()
+	if lineErr, ok := err.(*LineError); !ok || lineErr.Line != 4 {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if json, err := lr.Read(); err != nil || string(json.Bytes) != "last" {
+		t.Fatal("wrong last line", err)
+	}
+	if _, err := lr.Read(); err != io.EOF {
+		t.Fatal("expected EOF", err)
+	}
+
+	lr = NewLineReader(strings.NewReader("1\nx\n2 3\n" + strings.Repeat(" ", 10000) + "4"))
+	lr.SkipInvalid = true
+	lr.ReuseBuffer = true
+	var values []string
+	for {
+		json, err := lr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, string(json.Bytes))
+	}
+	if strings.Join(values, ",") != "1,4" || lr.Skipped != 2 {
+		t.Fatal("wrong values", values, lr.Skipped)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var buf bytes.Buffer
+	lw := NewLineWriter(&buf, MarshalOptions{Indent: "  ", SortKeys: true})
+	lw.Write(Unmarshal([]byte(`{"b": "x\ny", "a": [1]}`)))
+	lw.Write(Unmarshal([]byte(`2`)))
+	if buf.String() != "{\"a\":[1],\"b\":\"x\\ny\"}\n2\n" {
+		t.Fatal("wrong output", buf.String())
+	}
+}
+
+func TestSeqReader(t *testing.T) {
+	rs := "\x1e"
+	input := rs + `{"a": 1}` + "\n" + rs + rs + "12\n" + rs + "[1, 2" + rs + "true" + rs + "13" + rs + `"x"` + "\n junk\n" + rs + "14\n" + rs + "1,2\n" + rs + "null\n"
+	sr := NewSeqReader(iotest.OneByteReader(strings.NewReader(input)))
+	expected := []string{`{"a":1}`, `12`, `!truncated`, `!truncated`, `!truncated`, `"x"`, `!outside`, `14`, `!malformed`, `null`}
+	for _, e := range expected {
+		json, err := sr.Read()
+		switch e {
+		case "!truncated", "!outside", "!malformed":
+			seqErr, ok := err.(*SeqError)
+			if !ok || seqErr.Truncated != (e == "!truncated") || (e == "!outside") != (seqErr.Err == errOutsideRecord) {
+				t.Fatalf("expected %s error, got %v", e, err)
+			}
+		default:
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out := string(json.Marshal()); out != e {
+				t.Fatalf("expected %s, got %s", e, out)
+			}
+		}
+	}
+	if _, err := sr.Read(); err != io.EOF {
+		t.Fatal("expected EOF", err)
+	}
+
+	for _, input := range []string{"\x1e,", "\x1e]", "\x1e,\n\x1e1\n"} {
+		sr = NewSeqReader(strings.NewReader(input))
+		if _, err := sr.Read(); err == nil {
+			t.Fatalf("%q: expected error", input)
+		}
+	}
+	if json, err := sr.Read(); err != nil || string(json.Marshal()) != "1" {
+		t.Fatal("expected record after malformed one", err)
+	}
+}
+
+func TestSeqWriter(t *testing.T) {
+	var buf bytes.Buffer
+	sw := NewSeqWriter(&buf)
+	sw.Write(Unmarshal([]byte(`{"a": [1]}`)))
+	sw.Write(Unmarshal([]byte(`2`)))
+	if buf.String() != "\x1e{\"a\":[1]}\n\x1e2\n" {
+		t.Fatalf("wrong output %q", buf.String())
+	}
+	sr := NewSeqReader(&buf)
+	for i := 0; i < 2; i++ {
+		if _, err := sr.Read(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,967 @@
+package gojson
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+Transform is a compiled jq-style program, it is safe for concurrent use and reusable across documents
+supported subset of jq:
+
+	.  ..  .foo  ."foo"  .[expr]  .[from:to]  .[]  expr?
+	|  ,  //  and  or  == != < <= > >=  + - * / %  -expr
+	[expr]  {a, b: expr, "c": expr, (expr): expr, $var}  "text \(expr)"
+	expr as $name | body  reduce  foreach  if-then-elif-else-end  try-catch
+	and built-in functions like map, select, to_entries, from_entries, with_entries, sort_by, group_by
+*/
+type Transform struct {
+	expr string
+	body transformExpr
+}
+
+// CompileTransform parses jq-style program like `.items | map(select(.price > 10) | {name, total: .price * .qty})`
+func CompileTransform(expr string) (*Transform, error) {
+	p := transformParser{expr: expr}
+	body, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Transform{expr: expr, body: body}, nil
+}
+
+// MustCompileTransform is like CompileTransform but panics if the program is invalid
+func MustCompileTransform(expr string) *Transform {
+	t, err := CompileTransform(expr)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// String returns the source program
+func (t *Transform) String() string {
+	return t.expr
+}
+
+// Apply runs the program with g as input and returns all its outputs
+// outputs are new trees and g is never modified, runtime errors are returned as *EvalError
+// together with outputs produced before the error
+func (t *Transform) Apply(g *GoJSON) ([]*GoJSON, error) {
+	var results []*GoJSON
+	err := t.body.eval(nil, g, func(value *GoJSON) error {
+		results = append(results, value.Clone())
+		return nil
+	})
+	return results, err
+}
+
+// Transform compiles jq-style program and applies it to g, use CompileTransform to run the same program many times
+func (g *GoJSON) Transform(expr string) ([]*GoJSON, error) {
+	t, err := CompileTransform(expr)
+	if err != nil {
+		return nil, err
+	}
+	return t.Apply(g)
+}
+
+// EvalError is a runtime error of Transform, Value is the argument of error() or the message of built-in error
+// try-catch passes Value to the catch body
+type EvalError struct {
+	Value *GoJSON
+}
+
+func (e *EvalError) Error() string {
+	if e.Value.Type == JSONString {
+		return string(e.Value.Bytes)
+	}
+	return string(e.Value.Marshal()) + " (not a string)"
+}
+
+func evalErrorf(format string, args ...interface{}) error {
+	return &EvalError{Value: stringNode(fmt.Sprintf(format, args...))}
+}
+
+// stopEval ends a generator early like jq break, try doesn't catch it
+type stopEval struct {
+	label string
+}
+
+func (e *stopEval) Error() string {
+	return "break out of " + e.label
+}
+
+// region expressions
+
+// emitFunc receives outputs of an expression, an error stops the evaluation
+type emitFunc func(value *GoJSON) error
+
+// transformExpr is a jq filter, it emits zero or more outputs for the input
+// inputs are never modified, outputs may share nodes with them
+type transformExpr interface {
+	eval(env *transformEnv, in *GoJSON, emit emitFunc) error
+}
+
+// transformEnv is linked list of variable bindings, the innermost first
+type transformEnv struct {
+	name  string
+	value *GoJSON
+	next  *transformEnv
+}
+
+// lookup returns value of the variable, parser guarantees it is bound
+func (env *transformEnv) lookup(name string) *GoJSON {
+	for ; env != nil; env = env.next {
+		if env.name == name {
+			return env.value
+		}
+	}
+	return nullNode()
+}
+
+type identityExpr struct{}
+
+func (identityExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return emit(in)
+}
+
+type recurseExpr struct{}
+
+func (recurseExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return recurseNodes(in, emit)
+}
+
+// recurseNodes emits node and all its descendants in document order
+func recurseNodes(node *GoJSON, emit emitFunc) error {
+	if err := emit(node); err != nil {
+		return err
+	}
+	for _, child := range children(node) {
+		if err := recurseNodes(child, emit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type constExpr struct {
+	value *GoJSON
+}
+
+func (e *constExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return emit(e.value)
+}
+
+type varExpr struct {
+	name string
+}
+
+func (e *varExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return emit(env.lookup(e.name))
+}
+
+type pipeExpr struct {
+	left, right transformExpr
+}
+
+func (e *pipeExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.left.eval(env, in, func(value *GoJSON) error {
+		return e.right.eval(env, value, emit)
+	})
+}
+
+type commaExpr struct {
+	left, right transformExpr
+}
+
+func (e *commaExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	if err := e.left.eval(env, in, emit); err != nil {
+		return err
+	}
+	return e.right.eval(env, in, emit)
+}
+
+// bindExpr is "source as $name | body", body runs once for every output of source
+type bindExpr struct {
+	source transformExpr
+	name   string
+	body   transformExpr
+}
+
+func (e *bindExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.source.eval(env, in, func(value *GoJSON) error {
+		return e.body.eval(&transformEnv{name: e.name, value: value, next: env}, in, emit)
+	})
+}
+
+// indexExpr is .foo or target[index], index is evaluated against the same input as target
+type indexExpr struct {
+	target, index transformExpr
+}
+
+func (e *indexExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.target.eval(env, in, func(target *GoJSON) error {
+		return e.index.eval(env, in, func(index *GoJSON) error {
+			value, err := indexValue(target, index)
+			if err != nil {
+				return err
+			}
+			return emit(value)
+		})
+	})
+}
+
+// indexValue returns member of object or element of array, missing ones are null
+func indexValue(target, index *GoJSON) (*GoJSON, error) {
+	switch {
+	case target.Type == JSONObject && index.Type == JSONString:
+		if value, found := target.Map[string(index.Bytes)]; found {
+			return value, nil
+		}
+		return nullNode(), nil
+	case target.Type == JSONArray && isNumber(index):
+		f, _ := Number(index.Bytes).Float64()
+		i := math.Floor(f)
+		if i < 0 {
+			i += float64(len(target.Array))
+		}
+		if i >= 0 && i < float64(len(target.Array)) {
+			return target.Array[int(i)], nil
+		}
+		return nullNode(), nil
+	case target.Type == JSONNull && (index.Type == JSONString || isNumber(index)):
+		return nullNode(), nil
+	case index.Type == JSONString:
+		return nil, evalErrorf("cannot index %s with %q", typeName(target), index.Bytes)
+	}
+	return nil, evalErrorf("cannot index %s with %s", typeName(target), typeName(index))
+}
+
+// sliceExpr is target[from:to], missing bounds are nil
+type sliceExpr struct {
+	target, from, to transformExpr
+}
+
+func (e *sliceExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.target.eval(env, in, func(target *GoJSON) error {
+		return evalOptional(e.from, env, in, func(from *GoJSON) error {
+			return evalOptional(e.to, env, in, func(to *GoJSON) error {
+				value, err := sliceValue(target, from, to)
+				if err != nil {
+					return err
+				}
+				return emit(value)
+			})
+		})
+	})
+}
+
+// evalOptional emits null for missing expression
+func evalOptional(e transformExpr, env *transformEnv, in *GoJSON, emit emitFunc) error {
+	if e == nil {
+		return emit(nullNode())
+	}
+	return e.eval(env, in, emit)
+}
+
+// sliceValue slices array or string by code points, negative bounds count from the end
+func sliceValue(target, from, to *GoJSON) (*GoJSON, error) {
+	var length int
+	var runes []rune
+	switch target.Type {
+	case JSONNull:
+		return nullNode(), nil
+	case JSONArray:
+		length = len(target.Array)
+	case JSONString:
+		runes = []rune(string(target.Bytes))
+		length = len(runes)
+	default:
+		return nil, evalErrorf("cannot index %s with object", typeName(target))
+	}
+	start, end := 0, length
+	for i, bound := range []*GoJSON{from, to} {
+		if bound.Type == JSONNull {
+			continue
+		}
+		if !isNumber(bound) {
+			return nil, evalErrorf("start and end indices of a slice must be numbers")
+		}
+		f, _ := Number(bound.Bytes).Float64()
+		if i == 0 {
+			f = math.Floor(f)
+		} else {
+			f = math.Ceil(f)
+		}
+		if f < 0 {
+			f += float64(length)
+		}
+		f = math.Max(0, math.Min(f, float64(length)))
+		if i == 0 {
+			start = int(f)
+		} else {
+			end = int(f)
+		}
+	}
+	if end < start {
+		end = start
+	}
+	if target.Type == JSONString {
+		return stringNode(string(runes[start:end])), nil
+	}
+	return arrayNode(append([]*GoJSON(nil), target.Array[start:end]...)), nil
+}
+
+type iterateExpr struct {
+	target transformExpr
+}
+
+func (e *iterateExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.target.eval(env, in, func(target *GoJSON) error {
+		if target.Type != JSONArray && target.Type != JSONObject {
+			return evalErrorf("cannot iterate over %s", describe(target))
+		}
+		for _, value := range children(target) {
+			if err := emit(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// tryExpr suppresses errors of body or passes them to handler, errors of later filters are not caught
+type tryExpr struct {
+	body, handler transformExpr
+}
+
+func (e *tryExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	var downstream error
+	err := e.body.eval(env, in, func(value *GoJSON) error {
+		downstream = emit(value)
+		return downstream
+	})
+	if err == nil || err == downstream {
+		return err
+	}
+	evalErr, ok := err.(*EvalError)
+	if !ok {
+		return err
+	}
+	if e.handler == nil {
+		return nil
+	}
+	return e.handler.eval(env, evalErr.Value, emit)
+}
+
+// alternativeExpr is "left // right", it emits truthy outputs of left or all outputs of right if there are none
+type alternativeExpr struct {
+	left, right transformExpr
+}
+
+func (e *alternativeExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	found := false
+	var downstream error
+	err := e.left.eval(env, in, func(value *GoJSON) error {
+		if !truthy(value) {
+			return nil
+		}
+		found = true
+		downstream = emit(value)
+		return downstream
+	})
+	if err != nil {
+		if _, ok := err.(*EvalError); !ok || err == downstream {
+			return err
+		}
+	}
+	if found {
+		return nil
+	}
+	return e.right.eval(env, in, emit)
+}
+
+// logicExpr is "left and right" or "left or right", right is evaluated only when needed
+type logicExpr struct {
+	and         bool
+	left, right transformExpr
+}
+
+func (e *logicExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.left.eval(env, in, func(left *GoJSON) error {
+		if truthy(left) != e.and {
+			return emit(boolNode(!e.and))
+		}
+		return e.right.eval(env, in, func(right *GoJSON) error {
+			return emit(boolNode(truthy(right)))
+		})
+	})
+}
+
+// binaryExpr is arithmetic or comparison, like in jq outputs of right form the outer loop
+type binaryExpr struct {
+	op          string
+	left, right transformExpr
+}
+
+func (e *binaryExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.right.eval(env, in, func(right *GoJSON) error {
+		return e.left.eval(env, in, func(left *GoJSON) error {
+			value, err := binaryOp(e.op, left, right)
+			if err != nil {
+				return err
+			}
+			return emit(value)
+		})
+	})
+}
+
+type negateExpr struct {
+	body transformExpr
+}
+
+func (e *negateExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.body.eval(env, in, func(value *GoJSON) error {
+		if !isNumber(value) {
+			return evalErrorf("%s cannot be negated", describe(value))
+		}
+		return emit(negateNumber(value))
+	})
+}
+
+// arrayExpr collects all outputs of body into a new array, body is nil for []
+type arrayExpr struct {
+	body transformExpr
+}
+
+func (e *arrayExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	items, err := collect(e.body, env, in)
+	if err != nil {
+		return err
+	}
+	return emit(arrayNode(items))
+}
+
+// collect returns all outputs of e
+func collect(e transformExpr, env *transformEnv, in *GoJSON) ([]*GoJSON, error) {
+	items := []*GoJSON{}
+	if e == nil {
+		return items, nil
+	}
+	err := e.eval(env, in, func(value *GoJSON) error {
+		items = append(items, value)
+		return nil
+	})
+	return items, err
+}
+
+type objectEntry struct {
+	key, value transformExpr
+}
+
+// objectExpr builds an object for every combination of outputs of keys and values
+type objectExpr struct {
+	entries []objectEntry
+}
+
+func (e *objectExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	keys := make([]string, len(e.entries))
+	values := make([]*GoJSON, len(e.entries))
+	return e.build(env, in, keys, values, 0, emit)
+}
+
+func (e *objectExpr) build(env *transformEnv, in *GoJSON, keys []string, values []*GoJSON, i int, emit emitFunc) error {
+	if i == len(e.entries) {
+		obj := NewObject()
+		for j, key := range keys {
+			putField(obj, key, values[j])
+		}
+		return emit(obj)
+	}
+	return e.entries[i].key.eval(env, in, func(key *GoJSON) error {
+		if key.Type != JSONString {
+			return evalErrorf("object keys must be strings, got %s", describe(key))
+		}
+		return e.entries[i].value.eval(env, in, func(value *GoJSON) error {
+			keys[i], values[i] = string(key.Bytes), value
+			return e.build(env, in, keys, values, i+1, emit)
+		})
+	})
+}
+
+// stringExpr is interpolated string, parts are literal strings and \(expr) values
+type stringExpr struct {
+	parts []transformExpr
+}
+
+func (e *stringExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.interpolate(env, in, make([]string, len(e.parts)), len(e.parts)-1, emit)
+}
+
+// interpolate fills parts from the last one, so like in jq the last part forms the outer loop
+func (e *stringExpr) interpolate(env *transformEnv, in *GoJSON, parts []string, i int, emit emitFunc) error {
+	if i < 0 {
+		return emit(stringNode(strings.Join(parts, "")))
+	}
+	return e.parts[i].eval(env, in, func(value *GoJSON) error {
+		parts[i] = toString(value)
+		return e.interpolate(env, in, parts, i-1, emit)
+	})
+}
+
+// ifExpr is if-then-else, elif is nested ifExpr and missing else is identity
+type ifExpr struct {
+	cond, then, otherwise transformExpr
+}
+
+func (e *ifExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.cond.eval(env, in, func(cond *GoJSON) error {
+		if truthy(cond) {
+			return e.then.eval(env, in, emit)
+		}
+		return e.otherwise.eval(env, in, emit)
+	})
+}
+
+// reduceExpr is "reduce source as $name (init; update)", the last output of update becomes the state
+type reduceExpr struct {
+	source       transformExpr
+	name         string
+	init, update transformExpr
+}
+
+func (e *reduceExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.init.eval(env, in, func(state *GoJSON) error {
+		err := e.source.eval(env, in, func(value *GoJSON) error {
+			scope := &transformEnv{name: e.name, value: value, next: env}
+			next := nullNode()
+			err := e.update.eval(scope, state, func(value *GoJSON) error {
+				next = value
+				return nil
+			})
+			state = next
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return emit(state)
+	})
+}
+
+// foreachExpr is "foreach source as $name (init; update; extract)", it emits every intermediate state
+type foreachExpr struct {
+	source                transformExpr
+	name                  string
+	init, update, extract transformExpr
+}
+
+func (e *foreachExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.init.eval(env, in, func(state *GoJSON) error {
+		return e.source.eval(env, in, func(value *GoJSON) error {
+			scope := &transformEnv{name: e.name, value: value, next: env}
+			return e.update.eval(scope, state, func(value *GoJSON) error {
+				state = value
+				if e.extract == nil {
+					return emit(value)
+				}
+				return e.extract.eval(scope, value, emit)
+			})
+		})
+	})
+}
+
+// callExpr calls built-in function, args are filters evaluated by the function
+type callExpr struct {
+	name string
+	args []transformExpr
+	fn   transformBuiltin
+}
+
+func (e *callExpr) eval(env *transformEnv, in *GoJSON, emit emitFunc) error {
+	return e.fn(env, e.args, in, emit)
+}
+
+// endregion
+
+// region values
+
+func nullNode() *GoJSON {
+	return &GoJSON{Type: JSONNull, Bytes: []byte("null")}
+}
+
+func boolNode(b bool) *GoJSON {
+	return &GoJSON{Type: JSONBool, Bytes: []byte(strconv.FormatBool(b))}
+}
+
+func stringNode(s string) *GoJSON {
+	return &GoJSON{Type: JSONString, Bytes: []byte(s)}
+}
+
+// arrayNode returns array of items, items don't become children of it to keep inputs untouched
+func arrayNode(items []*GoJSON) *GoJSON {
+	return &GoJSON{Type: JSONArray, Array: items}
+}
+
+// putField sets member of object built by transform, unlike Set it doesn't attach value to obj
+func putField(obj *GoJSON, key string, value *GoJSON) {
+	if obj.Map == nil {
+		obj.Map = make(map[string]*GoJSON)
+	}
+	if _, found := obj.Map[key]; !found {
+		obj.keys = append(obj.keys, key)
+	}
+	obj.Map[key] = value
+}
+
+// copyObject returns shallow copy of object
+func copyObject(obj *GoJSON) *GoJSON {
+	result := NewObject()
+	for _, key := range obj.orderedKeys() {
+		putField(result, key, obj.Map[key])
+	}
+	return result
+}
+
+// numberText returns number node of valid json number text
+func numberText(s string) *GoJSON {
+	if strings.ContainsAny(s, ".eE") {
+		return &GoJSON{Type: JSONFloat, Bytes: []byte(s)}
+	}
+	return &GoJSON{Type: JSONInt, Bytes: []byte(s)}
+}
+
+// floatNode returns number node of f, integral values are written without fraction
+func floatNode(f float64) (*GoJSON, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, evalErrorf("number is out of range")
+	}
+	if abs := math.Abs(f); abs == 0 || abs >= 1e-5 && abs < 1e21 {
+		return numberText(strconv.FormatFloat(f, 'f', -1, 64)), nil
+	}
+	return numberText(strconv.FormatFloat(f, 'g', -1, 64)), nil
+}
+
+func negateNumber(n *GoJSON) *GoJSON {
+	if n.Bytes[0] == '-' {
+		return numberText(string(n.Bytes[1:]))
+	}
+	return numberText("-" + string(n.Bytes))
+}
+
+// truthy reports whether value is neither false nor null
+func truthy(value *GoJSON) bool {
+	switch value.Type {
+	case JSONNull, JSONInvalid:
+		return false
+	case JSONBool:
+		return string(value.Bytes) == "true"
+	}
+	return true
+}
+
+func typeName(value *GoJSON) string {
+	switch value.Type {
+	case JSONBool:
+		return "boolean"
+	case JSONInt, JSONFloat:
+		return "number"
+	case JSONString:
+		return "string"
+	case JSONArray:
+		return "array"
+	case JSONObject:
+		return "object"
+	}
+	return "null"
+}
+
+// describe returns type and shortened json of value for error messages
+func describe(value *GoJSON) string {
+	text := string(value.Marshal())
+	if len(text) > 11 {
+		cut := 10
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "..."
+	}
+	return typeName(value) + " (" + text + ")"
+}
+
+// toString returns strings as is and other values as json
+func toString(value *GoJSON) string {
+	if value.Type == JSONString {
+		return string(value.Bytes)
+	}
+	return string(value.Marshal())
+}
+
+// typeRank orders values of different types: null < false < true < numbers < strings < arrays < objects
+func typeRank(value *GoJSON) int {
+	switch value.Type {
+	case JSONBool:
+		if truthy(value) {
+			return 2
+		}
+		return 1
+	case JSONInt, JSONFloat:
+		return 3
+	case JSONString:
+		return 4
+	case JSONArray:
+		return 5
+	case JSONObject:
+		return 6
+	}
+	return 0
+}
+
+// compareValues is jq order of values, objects are compared by sorted keys first and then by values
+func compareValues(a, b *GoJSON) int {
+	if ra, rb := typeRank(a), typeRank(b); ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+	switch a.Type {
+	case JSONInt, JSONFloat:
+		return compareNumbers(a.Bytes, b.Bytes)
+	case JSONString:
+		return bytes.Compare(a.Bytes, b.Bytes)
+	case JSONArray:
+		for i := 0; i < len(a.Array) && i < len(b.Array); i++ {
+			if c := compareValues(a.Array[i], b.Array[i]); c != 0 {
+				return c
+			}
+		}
+		return len(a.Array) - len(b.Array)
+	case JSONObject:
+		keysA, keysB := sortedKeys(a), sortedKeys(b)
+		for i := 0; i < len(keysA) && i < len(keysB); i++ {
+			if c := strings.Compare(keysA[i], keysB[i]); c != 0 {
+				return c
+			}
+		}
+		if len(keysA) != len(keysB) {
+			return len(keysA) - len(keysB)
+		}
+		for _, key := range keysA {
+			if c := compareValues(a.Map[key], b.Map[key]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func sortedKeys(obj *GoJSON) []string {
+	keys := append([]string(nil), obj.orderedKeys()...)
+	sort.Strings(keys)
+	return keys
+}
+
+// endregion
+
+// region operators
+
+func binaryOp(op string, left, right *GoJSON) (*GoJSON, error) {
+	switch op {
+	case "==":
+		return boolNode(compareValues(left, right) == 0), nil
+	case "!=":
+		return boolNode(compareValues(left, right) != 0), nil
+	case "<":
+		return boolNode(compareValues(left, right) < 0), nil
+	case "<=":
+		return boolNode(compareValues(left, right) <= 0), nil
+	case ">":
+		return boolNode(compareValues(left, right) > 0), nil
+	case ">=":
+		return boolNode(compareValues(left, right) >= 0), nil
+	case "+":
+		return addValues(left, right)
+	case "-":
+		return subtractValues(left, right)
+	case "*":
+		return multiplyValues(left, right)
+	case "/":
+		return divideValues(left, right)
+	}
+	if isNumber(left) && isNumber(right) {
+		return numberOp('%', left, right)
+	}
+	return nil, evalErrorf("%s and %s cannot be divided", describe(left), describe(right))
+}
+
+// addValues adds numbers, concatenates strings and arrays, merges objects, null is neutral
+func addValues(left, right *GoJSON) (*GoJSON, error) {
+	switch {
+	case left.Type == JSONNull:
+		return right, nil
+	case right.Type == JSONNull:
+		return left, nil
+	case isNumber(left) && isNumber(right):
+		return numberOp('+', left, right)
+	case left.Type == JSONString && right.Type == JSONString:
+		return stringNode(string(left.Bytes) + string(right.Bytes)), nil
+	case left.Type == JSONArray && right.Type == JSONArray:
+		items := make([]*GoJSON, 0, len(left.Array)+len(right.Array))
+		return arrayNode(append(append(items, left.Array...), right.Array...)), nil
+	case left.Type == JSONObject && right.Type == JSONObject:
+		result := copyObject(left)
+		for _, key := range right.orderedKeys() {
+			putField(result, key, right.Map[key])
+		}
+		return result, nil
+	}
+	return nil, evalErrorf("%s and %s cannot be added", describe(left), describe(right))
+}
+
+// subtractValues subtracts numbers or removes all elements of right array from left one
+func subtractValues(left, right *GoJSON) (*GoJSON, error) {
+	switch {
+	case isNumber(left) && isNumber(right):
+		return numberOp('-', left, right)
+	case left.Type == JSONArray && right.Type == JSONArray:
+		items := []*GoJSON{}
+		for _, item := range left.Array {
+			if indexOf(right.Array, item) < 0 {
+				items = append(items, item)
+			}
+		}
+		return arrayNode(items), nil
+	}
+	return nil, evalErrorf("%s and %s cannot be subtracted", describe(left), describe(right))
+}
+
+// multiplyValues multiplies numbers, repeats strings and merges objects recursively
+func multiplyValues(left, right *GoJSON) (*GoJSON, error) {
+	switch {
+	case isNumber(left) && isNumber(right):
+		return numberOp('*', left, right)
+	case left.Type == JSONString && isNumber(right):
+		return repeatString(left, right)
+	case isNumber(left) && right.Type == JSONString:
+		return repeatString(right, left)
+	case left.Type == JSONObject && right.Type == JSONObject:
+		return deepMerge(left, right), nil
+	}
+	return nil, evalErrorf("%s and %s cannot be multiplied", describe(left), describe(right))
+}
+
+// repeatString returns s repeated n times, null if n isn't positive
+func repeatString(s, n *GoJSON) (*GoJSON, error) {
+	f, _ := Number(n.Bytes).Float64()
+	if f < 1 {
+		return nullNode(), nil
+	}
+	if f*float64(len(s.Bytes)) > math.MaxInt32 {
+		return nil, evalErrorf("repeated string is too long")
+	}
+	return stringNode(strings.Repeat(string(s.Bytes), int(f))), nil
+}
+
+func deepMerge(left, right *GoJSON) *GoJSON {
+	result := copyObject(left)
+	for _, key := range right.orderedKeys() {
+		value := right.Map[key]
+		if current, found := result.Map[key]; found && current.Type == JSONObject && value.Type == JSONObject {
+			value = deepMerge(current, value)
+		}
+		putField(result, key, value)
+	}
+	return result
+}
+
+// divideValues divides numbers or splits string by separator
+func divideValues(left, right *GoJSON) (*GoJSON, error) {
+	switch {
+	case isNumber(left) && isNumber(right):
+		return numberOp('/', left, right)
+	case left.Type == JSONString && right.Type == JSONString:
+		return splitString(string(left.Bytes), string(right.Bytes)), nil
+	}
+	return nil, evalErrorf("%s and %s cannot be divided", describe(left), describe(right))
+}
+
+func splitString(s, sep string) *GoJSON {
+	items := []*GoJSON{}
+	if s == "" {
+		return arrayNode(items)
+	}
+	for _, part := range strings.Split(s, sep) {
+		items = append(items, stringNode(part))
+	}
+	return arrayNode(items)
+}
+
+// numberOp computes integers exactly and other numbers as float64
+func numberOp(op byte, left, right *GoJSON) (*GoJSON, error) {
+	if left.Type == JSONInt && right.Type == JSONInt {
+		a, errA := Number(left.Bytes).BigInt()
+		b, errB := Number(right.Bytes).BigInt()
+		if errA == nil && errB == nil {
+			if (op == '/' || op == '%') && b.Sign() == 0 {
+				return nil, evalErrorf("%s and %s cannot be divided because the divisor is zero", describe(left), describe(right))
+			}
+			switch op {
+			case '+':
+				return numberText(a.Add(a, b).String()), nil
+			case '-':
+				return numberText(a.Sub(a, b).String()), nil
+			case '*':
+				return numberText(a.Mul(a, b).String()), nil
+			case '%':
+				return numberText(a.Rem(a, b).String()), nil
+			}
+			if q, r := new(big.Int).QuoRem(a, b, new(big.Int)); r.Sign() == 0 {
+				return numberText(q.String()), nil
+			}
+		}
+	}
+	x, errX := Number(left.Bytes).Float64()
+	y, errY := Number(right.Bytes).Float64()
+	if errX != nil || errY != nil {
+		return nil, evalErrorf("number is out of range")
+	}
+	var result float64
+	switch op {
+	case '+':
+		result = x + y
+	case '-':
+		result = x - y
+	case '*':
+		result = x * y
+	case '/':
+		if y == 0 {
+			return nil, evalErrorf("%s and %s cannot be divided because the divisor is zero", describe(left), describe(right))
+		}
+		result = x / y
+	case '%':
+		if math.Trunc(y) == 0 {
+			return nil, evalErrorf("%s and %s cannot be divided because the divisor is zero", describe(left), describe(right))
+		}
+		result = math.Mod(math.Trunc(x), math.Trunc(y))
+	}
+	return floatNode(result)
+}
+
+// indexOf returns position of the first element equal to value or -1
+func indexOf(items []*GoJSON, value *GoJSON) int {
+	for i, item := range items {
+		if compareValues(item, value) == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// endregion
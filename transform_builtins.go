@@ -0,0 +1,708 @@
+package gojson
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// transformBuiltin implements built-in function, args are filters evaluated by the function itself
+type transformBuiltin func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error
+
+// transformBuiltins are built-in functions by name and arity
+var transformBuiltins = map[string]transformBuiltin{
+	"empty/0": func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		return nil
+	},
+	"error/0": func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		return &EvalError{Value: in}
+	},
+	"error/1": func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		return args[0].eval(env, in, func(value *GoJSON) error {
+			return &EvalError{Value: value}
+		})
+	},
+	"not/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return boolNode(!truthy(in)), nil
+	}),
+	"type/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return stringNode(typeName(in)), nil
+	}),
+	"length/0":         valueFunc(lengthOf),
+	"utf8bytelength/0": valueFunc(utf8ByteLength),
+	"keys/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return keysOf(in, true)
+	}),
+	"keys_unsorted/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return keysOf(in, false)
+	}),
+	"has/1":        argFunc(hasKey),
+	"contains/1":   argFunc(contains),
+	"map/1":        builtinMap,
+	"map_values/1": builtinMapValues,
+	"select/1": func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		return args[0].eval(env, in, func(value *GoJSON) error {
+			if truthy(value) {
+				return emit(in)
+			}
+			return nil
+		})
+	},
+	"recurse/0": func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		return recurseNodes(in, emit)
+	},
+	"recurse/1":      builtinRecurse,
+	"to_entries/0":   valueFunc(toEntries),
+	"from_entries/0": valueFunc(fromEntries),
+	"with_entries/1": builtinWithEntries,
+	"add/0":          valueFunc(addAll),
+	"any/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return anyAll(in, true)
+	}),
+	"all/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return anyAll(in, false)
+	}),
+	"range/1": func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		return args[0].eval(env, in, func(upto *GoJSON) error {
+			return emitRange(numberNode(0), upto, emit)
+		})
+	},
+	"range/2": func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		return args[0].eval(env, in, func(from *GoJSON) error {
+			return args[1].eval(env, in, func(upto *GoJSON) error {
+				return emitRange(from, upto, emit)
+			})
+		})
+	},
+	"floor/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		if in.Type == JSONInt {
+			return in, nil
+		}
+		return mathFunc(in, math.Floor)
+	}),
+	"sqrt/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return mathFunc(in, math.Sqrt)
+	}),
+	"tostring/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		if in.Type == JSONString {
+			return in, nil
+		}
+		return stringNode(toString(in)), nil
+	}),
+	"tonumber/0": valueFunc(toNumber),
+	"tojson/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return stringNode(string(in.Marshal())), nil
+	}),
+	"fromjson/0":  valueFunc(fromJSON),
+	"sort/0":      sortBuiltin("sort"),
+	"sort_by/1":   sortBuiltin("sort"),
+	"group_by/1":  sortBuiltin("group"),
+	"unique/0":    sortBuiltin("unique"),
+	"unique_by/1": sortBuiltin("unique"),
+	"min/0":       extremumBuiltin(false),
+	"min_by/1":    extremumBuiltin(false),
+	"max/0":       extremumBuiltin(true),
+	"max_by/1":    extremumBuiltin(true),
+	"reverse/0":   valueFunc(reverse),
+	"flatten/0":   valueFunc(flatten),
+	"join/1":      argFunc(join),
+	"split/1": argFunc(func(in, sep *GoJSON) (*GoJSON, error) {
+		if in.Type != JSONString || sep.Type != JSONString {
+			return nil, evalErrorf("split input and separator must be strings")
+		}
+		return splitString(string(in.Bytes), string(sep.Bytes)), nil
+	}),
+	"ascii_downcase/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return mapASCII(in, 'A', 'Z', 'a'-'A')
+	}),
+	"ascii_upcase/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return mapASCII(in, 'a', 'z', 'A'-'a')
+	}),
+	"startswith/1": argFunc(func(in, prefix *GoJSON) (*GoJSON, error) {
+		if in.Type != JSONString || prefix.Type != JSONString {
+			return nil, evalErrorf("startswith() requires string inputs")
+		}
+		return boolNode(bytes.HasPrefix(in.Bytes, prefix.Bytes)), nil
+	}),
+	"endswith/1": argFunc(func(in, suffix *GoJSON) (*GoJSON, error) {
+		if in.Type != JSONString || suffix.Type != JSONString {
+			return nil, evalErrorf("endswith() requires string inputs")
+		}
+		return boolNode(bytes.HasSuffix(in.Bytes, suffix.Bytes)), nil
+	}),
+	"ltrimstr/1": argFunc(func(in, prefix *GoJSON) (*GoJSON, error) {
+		if in.Type == JSONString && prefix.Type == JSONString && bytes.HasPrefix(in.Bytes, prefix.Bytes) {
+			return stringNode(string(in.Bytes[len(prefix.Bytes):])), nil
+		}
+		return in, nil
+	}),
+	"rtrimstr/1": argFunc(func(in, suffix *GoJSON) (*GoJSON, error) {
+		if in.Type == JSONString && suffix.Type == JSONString && bytes.HasSuffix(in.Bytes, suffix.Bytes) {
+			return stringNode(string(in.Bytes[:len(in.Bytes)-len(suffix.Bytes)])), nil
+		}
+		return in, nil
+	}),
+	"first/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return indexValue(in, numberNode(0))
+	}),
+	"last/0": valueFunc(func(in *GoJSON) (*GoJSON, error) {
+		return indexValue(in, numberNode(-1))
+	}),
+	"first/1": func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		value, err := firstOutput(args[0], env, in)
+		if err != nil || value == nil {
+			return err
+		}
+		return emit(value)
+	},
+	"limit/2": builtinLimit,
+}
+
+// valueFunc wraps function of the input
+func valueFunc(fn func(in *GoJSON) (*GoJSON, error)) transformBuiltin {
+	return func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		value, err := fn(in)
+		if err != nil {
+			return err
+		}
+		return emit(value)
+	}
+}
+
+// argFunc wraps function of the input and the argument value, it is called for every output of the argument
+func argFunc(fn func(in, arg *GoJSON) (*GoJSON, error)) transformBuiltin {
+	return func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		return args[0].eval(env, in, func(arg *GoJSON) error {
+			value, err := fn(in, arg)
+			if err != nil {
+				return err
+			}
+			return emit(value)
+		})
+	}
+}
+
+// firstOutput returns the first output of e or nil if there is none, e isn't evaluated further
+func firstOutput(e transformExpr, env *transformEnv, in *GoJSON) (*GoJSON, error) {
+	var first *GoJSON
+	stop := &stopEval{label: "first"}
+	err := e.eval(env, in, func(value *GoJSON) error {
+		first = value
+		return stop
+	})
+	if err == stop {
+		err = nil
+	}
+	return first, err
+}
+
+func lengthOf(in *GoJSON) (*GoJSON, error) {
+	switch in.Type {
+	case JSONNull:
+		return numberNode(0), nil
+	case JSONInt, JSONFloat:
+		if in.Bytes[0] == '-' {
+			return negateNumber(in), nil
+		}
+		return in, nil
+	case JSONString:
+		return numberNode(utf8.RuneCount(in.Bytes)), nil
+	case JSONArray, JSONObject:
+		return numberNode(in.Len()), nil
+	}
+	return nil, evalErrorf("%s has no length", describe(in))
+}
+
+func utf8ByteLength(in *GoJSON) (*GoJSON, error) {
+	if in.Type != JSONString {
+		return nil, evalErrorf("%s only strings have UTF-8 byte length", describe(in))
+	}
+	return numberNode(len(in.Bytes)), nil
+}
+
+// keysOf returns keys of object or indexes of array
+func keysOf(in *GoJSON, sorted bool) (*GoJSON, error) {
+	items := []*GoJSON{}
+	switch in.Type {
+	case JSONObject:
+		keys := in.orderedKeys()
+		if sorted {
+			keys = sortedKeys(in)
+		}
+		for _, key := range keys {
+			items = append(items, stringNode(key))
+		}
+	case JSONArray:
+		for i := range in.Array {
+			items = append(items, numberNode(i))
+		}
+	default:
+		return nil, evalErrorf("%s has no keys", describe(in))
+	}
+	return arrayNode(items), nil
+}
+
+func hasKey(in, key *GoJSON) (*GoJSON, error) {
+	switch {
+	case in.Type == JSONObject && key.Type == JSONString:
+		_, found := in.Map[string(key.Bytes)]
+		return boolNode(found), nil
+	case in.Type == JSONArray && isNumber(key):
+		f, _ := Number(key.Bytes).Float64()
+		return boolNode(f >= 0 && f < float64(len(in.Array))), nil
+	}
+	return nil, evalErrorf("cannot check whether %s has a %s key", typeName(in), typeName(key))
+}
+
+func contains(in, value *GoJSON) (*GoJSON, error) {
+	if typeName(in) != typeName(value) {
+		return nil, evalErrorf("%s and %s cannot have their containment checked", describe(in), describe(value))
+	}
+	return boolNode(containsValue(in, value)), nil
+}
+
+// containsValue reports whether a contains b: substrings, subsets of arrays and objects, equal scalars
+func containsValue(a, b *GoJSON) bool {
+	switch {
+	case a.Type == JSONObject && b.Type == JSONObject:
+		for key, value := range b.Map {
+			if other, found := a.Map[key]; !found || !containsValue(other, value) {
+				return false
+			}
+		}
+		return true
+	case a.Type == JSONArray && b.Type == JSONArray:
+		for _, value := range b.Array {
+			found := false
+			for _, other := range a.Array {
+				if containsValue(other, value) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	case a.Type == JSONString && b.Type == JSONString:
+		return bytes.Contains(a.Bytes, b.Bytes)
+	}
+	return typeName(a) == typeName(b) && compareValues(a, b) == 0
+}
+
+// builtinMap collects outputs of f for every element of array or value of object
+func builtinMap(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+	if in.Type != JSONArray && in.Type != JSONObject {
+		return evalErrorf("cannot iterate over %s", describe(in))
+	}
+	items := []*GoJSON{}
+	for _, value := range children(in) {
+		err := args[0].eval(env, value, func(value *GoJSON) error {
+			items = append(items, value)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return emit(arrayNode(items))
+}
+
+// builtinMapValues replaces every value with the first output of f, values without outputs are removed
+func builtinMapValues(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+	switch in.Type {
+	case JSONArray:
+		items := []*GoJSON{}
+		for _, value := range in.Array {
+			value, err := firstOutput(args[0], env, value)
+			if err != nil {
+				return err
+			}
+			if value != nil {
+				items = append(items, value)
+			}
+		}
+		return emit(arrayNode(items))
+	case JSONObject:
+		result := NewObject()
+		for _, key := range in.orderedKeys() {
+			value, err := firstOutput(args[0], env, in.Map[key])
+			if err != nil {
+				return err
+			}
+			if value != nil {
+				putField(result, key, value)
+			}
+		}
+		return emit(result)
+	}
+	return evalErrorf("cannot iterate over %s", describe(in))
+}
+
+// builtinRecurse emits the input and recursively outputs of f
+func builtinRecurse(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+	if err := emit(in); err != nil {
+		return err
+	}
+	return args[0].eval(env, in, func(value *GoJSON) error {
+		return builtinRecurse(env, args, value, emit)
+	})
+}
+
+// toEntries converts object to array of {"key": k, "value": v}, array indexes become keys
+func toEntries(in *GoJSON) (*GoJSON, error) {
+	items := []*GoJSON{}
+	switch in.Type {
+	case JSONObject:
+		for _, key := range in.orderedKeys() {
+			items = append(items, entryNode(stringNode(key), in.Map[key]))
+		}
+	case JSONArray:
+		for i, value := range in.Array {
+			items = append(items, entryNode(numberNode(i), value))
+		}
+	default:
+		return nil, evalErrorf("%s has no keys", describe(in))
+	}
+	return arrayNode(items), nil
+}
+
+func entryNode(key, value *GoJSON) *GoJSON {
+	entry := NewObject()
+	putField(entry, "key", key)
+	putField(entry, "value", value)
+	return entry
+}
+
+// entryKeys are the names from_entries accepts for the key of an entry
+var entryKeys = []string{"key", "k", "name", "Name", "K", "Key"}
+
+// fromEntries converts array of entries to object, keys which aren't strings are converted to json
+func fromEntries(in *GoJSON) (*GoJSON, error) {
+	if in.Type != JSONArray {
+		return nil, evalErrorf("cannot iterate over %s", describe(in))
+	}
+	result := NewObject()
+	for _, entry := range in.Array {
+		if entry.Type != JSONObject {
+			return nil, evalErrorf("cannot index %s with \"key\"", typeName(entry))
+		}
+		key := nullNode()
+		for _, name := range entryKeys {
+			if value, found := entry.Map[name]; found && truthy(value) {
+				key = value
+				break
+			}
+		}
+		value, found := entry.Map["value"]
+		if !found {
+			value = entry.Map["v"]
+		}
+		if value == nil {
+			value = nullNode()
+		}
+		putField(result, toString(key), value)
+	}
+	return result, nil
+}
+
+// builtinWithEntries is to_entries | map(f) | from_entries
+func builtinWithEntries(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+	entries, err := toEntries(in)
+	if err != nil {
+		return err
+	}
+	var mapped *GoJSON
+	err = builtinMap(env, args, entries, func(value *GoJSON) error {
+		mapped = value
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	result, err := fromEntries(mapped)
+	if err != nil {
+		return err
+	}
+	return emit(result)
+}
+
+// addAll adds all elements of array or values of object, the sum of none is null
+func addAll(in *GoJSON) (*GoJSON, error) {
+	if in.Type == JSONNull {
+		return in, nil
+	}
+	if in.Type != JSONArray && in.Type != JSONObject {
+		return nil, evalErrorf("cannot iterate over %s", describe(in))
+	}
+	sum := nullNode()
+	for _, value := range children(in) {
+		var err error
+		if sum, err = addValues(sum, value); err != nil {
+			return nil, err
+		}
+	}
+	return sum, nil
+}
+
+func anyAll(in *GoJSON, any bool) (*GoJSON, error) {
+	if in.Type != JSONArray && in.Type != JSONObject {
+		return nil, evalErrorf("cannot iterate over %s", describe(in))
+	}
+	for _, value := range children(in) {
+		if truthy(value) == any {
+			return boolNode(any), nil
+		}
+	}
+	return boolNode(!any), nil
+}
+
+// emitRange emits numbers from from up to but not including upto
+func emitRange(from, upto *GoJSON, emit emitFunc) error {
+	if !isNumber(from) || !isNumber(upto) {
+		return evalErrorf("range bounds must be numbers")
+	}
+	start, _ := Number(from.Bytes).Float64()
+	end, _ := Number(upto.Bytes).Float64()
+	for f := start; f < end; f++ {
+		value, err := floatNode(f)
+		if err != nil {
+			return err
+		}
+		if err := emit(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mathFunc(in *GoJSON, fn func(float64) float64) (*GoJSON, error) {
+	if !isNumber(in) {
+		return nil, evalErrorf("%s number required", describe(in))
+	}
+	f, err := Number(in.Bytes).Float64()
+	if err != nil {
+		return nil, evalErrorf("number is out of range")
+	}
+	return floatNode(fn(f))
+}
+
+func toNumber(in *GoJSON) (*GoJSON, error) {
+	switch in.Type {
+	case JSONInt, JSONFloat:
+		return in, nil
+	case JSONString:
+		if node, err := Parse(in.Bytes, ParseOptions{Strict: true}); err == nil && isNumber(node) {
+			return node, nil
+		}
+		return nil, evalErrorf("cannot parse %q as number", in.Bytes)
+	}
+	return nil, evalErrorf("%s cannot be parsed as a number", describe(in))
+}
+
+func fromJSON(in *GoJSON) (*GoJSON, error) {
+	if in.Type != JSONString {
+		return nil, evalErrorf("%s cannot be parsed as json", describe(in))
+	}
+	node, err := Parse(in.Bytes, ParseOptions{Strict: true})
+	if err != nil {
+		return nil, evalErrorf("%v (while parsing %q)", err, in.Bytes)
+	}
+	return node, nil
+}
+
+// keyedItem is array element with its sort key
+type keyedItem struct {
+	key, value *GoJSON
+}
+
+// sortItems stably sorts array by [f] of elements or by elements themselves if f is nil
+func sortItems(env *transformEnv, f transformExpr, in *GoJSON) ([]keyedItem, error) {
+	if in.Type != JSONArray {
+		return nil, evalErrorf("%s cannot be sorted, as it is not an array", describe(in))
+	}
+	items := make([]keyedItem, len(in.Array))
+	for i, value := range in.Array {
+		key := value
+		if f != nil {
+			keys, err := collect(f, env, value)
+			if err != nil {
+				return nil, err
+			}
+			key = arrayNode(keys)
+		}
+		items[i] = keyedItem{key: key, value: value}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return compareValues(items[i].key, items[j].key) < 0
+	})
+	return items, nil
+}
+
+// sortBuiltin implements sort, group and unique functions, the optional argument computes sort keys
+func sortBuiltin(mode string) transformBuiltin {
+	return func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		var f transformExpr
+		if len(args) > 0 {
+			f = args[0]
+		}
+		items, err := sortItems(env, f, in)
+		if err != nil {
+			return err
+		}
+		result := []*GoJSON{}
+		for i, item := range items {
+			same := i > 0 && compareValues(items[i-1].key, item.key) == 0
+			switch {
+			case mode == "group" && same:
+				group := result[len(result)-1]
+				group.Array = append(group.Array, item.value)
+			case mode == "group":
+				result = append(result, arrayNode([]*GoJSON{item.value}))
+			case mode == "unique" && same:
+			default:
+				result = append(result, item.value)
+			}
+		}
+		return emit(arrayNode(result))
+	}
+}
+
+// extremumBuiltin implements min and max functions, min returns the first minimal element and max the last maximal
+func extremumBuiltin(max bool) transformBuiltin {
+	return func(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+		if in.Type != JSONArray {
+			return evalErrorf("%s cannot be compared, as it is not an array", describe(in))
+		}
+		var best, bestKey *GoJSON
+		for _, value := range in.Array {
+			key := value
+			if len(args) > 0 {
+				keys, err := collect(args[0], env, value)
+				if err != nil {
+					return err
+				}
+				key = arrayNode(keys)
+			}
+			if bestKey == nil {
+				best, bestKey = value, key
+				continue
+			}
+			if c := compareValues(key, bestKey); max && c >= 0 || !max && c < 0 {
+				best, bestKey = value, key
+			}
+		}
+		if best == nil {
+			best = nullNode()
+		}
+		return emit(best)
+	}
+}
+
+func reverse(in *GoJSON) (*GoJSON, error) {
+	switch in.Type {
+	case JSONNull:
+		return arrayNode([]*GoJSON{}), nil
+	case JSONString:
+		runes := []rune(string(in.Bytes))
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return stringNode(string(runes)), nil
+	case JSONArray:
+		items := make([]*GoJSON, len(in.Array))
+		for i, value := range in.Array {
+			items[len(items)-1-i] = value
+		}
+		return arrayNode(items), nil
+	}
+	return nil, evalErrorf("cannot reverse %s", describe(in))
+}
+
+func flatten(in *GoJSON) (*GoJSON, error) {
+	if in.Type != JSONArray {
+		return nil, evalErrorf("cannot flatten %s", describe(in))
+	}
+	return arrayNode(flattenInto([]*GoJSON{}, in)), nil
+}
+
+func flattenInto(items []*GoJSON, array *GoJSON) []*GoJSON {
+	for _, value := range array.Array {
+		if value.Type == JSONArray {
+			items = flattenInto(items, value)
+		} else {
+			items = append(items, value)
+		}
+	}
+	return items
+}
+
+// join concatenates elements of array with separator, null is an empty string and numbers and booleans are converted
+func join(in, sep *GoJSON) (*GoJSON, error) {
+	if in.Type != JSONArray {
+		return nil, evalErrorf("cannot iterate over %s", describe(in))
+	}
+	if sep.Type != JSONString {
+		return nil, evalErrorf("%s separator must be a string", describe(sep))
+	}
+	var b strings.Builder
+	for i, value := range in.Array {
+		if i > 0 {
+			b.Write(sep.Bytes)
+		}
+		switch value.Type {
+		case JSONNull:
+		case JSONString, JSONInt, JSONFloat, JSONBool:
+			b.Write(value.Bytes)
+		default:
+			return nil, evalErrorf("cannot join with %s", describe(value))
+		}
+	}
+	return stringNode(b.String()), nil
+}
+
+// mapASCII shifts ASCII letters between from and to by delta
+func mapASCII(in *GoJSON, from, to byte, delta int) (*GoJSON, error) {
+	if in.Type != JSONString {
+		return nil, evalErrorf("%s cannot change case, as it is not a string", describe(in))
+	}
+	out := make([]byte, len(in.Bytes))
+	for i, c := range in.Bytes {
+		if c >= from && c <= to {
+			c = byte(int(c) + delta)
+		}
+		out[i] = c
+	}
+	return &GoJSON{Type: JSONString, Bytes: out}, nil
+}
+
+// builtinLimit emits at most n outputs of f
+func builtinLimit(env *transformEnv, args []transformExpr, in *GoJSON, emit emitFunc) error {
+	return args[0].eval(env, in, func(n *GoJSON) error {
+		if !isNumber(n) {
+			return evalErrorf("limit count must be a number")
+		}
+		limit, _ := Number(n.Bytes).Float64()
+		if limit <= 0 {
+			return nil
+		}
+		stop := &stopEval{label: "limit"}
+		count := 0
+		err := args[1].eval(env, in, func(value *GoJSON) error {
+			if err := emit(value); err != nil {
+				return err
+			}
+			if count++; float64(count) >= limit {
+				return stop
+			}
+			return nil
+		})
+		if err == stop {
+			return nil
+		}
+		return err
+	})
+}
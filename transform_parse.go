@@ -0,0 +1,618 @@
+package gojson
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TransformError describes invalid transform program
+type TransformError struct {
+	Expr   string
+	Offset int // byte offset of the error in Expr
+	Msg    string
+}
+
+func (e *TransformError) Error() string {
+	return fmt.Sprintf("invalid transform %q at offset %d: %s", e.Expr, e.Offset, e.Msg)
+}
+
+// transformParser is recursive descent parser of jq subset, like json parser it panics with the error
+type transformParser struct {
+	expr string
+	pos  int
+	vars []string // variables in scope, the innermost last
+	// bounds of the last parsed postfix term including blank space after it, "as" may follow only a term
+	termStart, termEnd int
+}
+
+// transformKeywords can't be used as function names
+var transformKeywords = map[string]bool{
+	"and": true, "or": true, "as": true, "if": true, "then": true, "elif": true, "else": true, "end": true,
+	"reduce": true, "foreach": true, "try": true, "catch": true, "def": true, "label": true,
+}
+
+func (p *transformParser) fail(msg string) {
+	panic(&TransformError{Expr: p.expr, Offset: p.pos, Msg: msg})
+}
+
+func (p *transformParser) failAt(pos int, msg string) {
+	p.pos = pos
+	p.fail(msg)
+}
+
+func (p *transformParser) parse() (body transformExpr, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			transformErr, ok := r.(*TransformError)
+			if !ok {
+				panic(r)
+			}
+			err = transformErr
+		}
+	}()
+	body = p.parsePipe()
+	p.skipBlank()
+	if p.pos != len(p.expr) {
+		p.fail("unexpected character")
+	}
+	return body, nil
+}
+
+func (p *transformParser) peek() byte {
+	if p.pos < len(p.expr) {
+		return p.expr[p.pos]
+	}
+	return 0
+}
+
+func (p *transformParser) peekAt(offset int) byte {
+	if p.pos+offset < len(p.expr) {
+		return p.expr[p.pos+offset]
+	}
+	return 0
+}
+
+// consume skips blank space and consumes c if it is next
+func (p *transformParser) consume(c byte) bool {
+	p.skipBlank()
+	if p.peek() == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *transformParser) expect(c byte) {
+	if !p.consume(c) {
+		p.fail(fmt.Sprintf("expected '%c'", c))
+	}
+}
+
+// skipBlank skips whitespace and comments
+func (p *transformParser) skipBlank() {
+	for p.pos < len(p.expr) {
+		switch p.expr[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		case '#':
+			for p.pos < len(p.expr) && p.expr[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// operator consumes the first of ops found next, longer operators must come first
+// assignment operators like |= and += are reported as unsupported
+func (p *transformParser) operator(ops ...string) string {
+	p.skipBlank()
+	rest := p.expr[p.pos:]
+	for _, op := range ops {
+		if !strings.HasPrefix(rest, op) {
+			continue
+		}
+		next := rest[len(op):]
+		if op == "/" && strings.HasPrefix(next, "/") {
+			continue
+		}
+		if strings.HasPrefix(next, "=") && strings.IndexByte("=<>!", op[len(op)-1]) < 0 {
+			p.fail("assignment operators are not supported")
+		}
+		p.pos += len(op)
+		return op
+	}
+	return ""
+}
+
+// keyword consumes word if it is next and isn't a prefix of a longer identifier
+func (p *transformParser) keyword(word string) bool {
+	p.skipBlank()
+	if strings.HasPrefix(p.expr[p.pos:], word) && !isIdentChar(p.peekAt(len(word)), true) {
+		p.pos += len(word)
+		return true
+	}
+	return false
+}
+
+func (p *transformParser) expectKeyword(word string) {
+	if !p.keyword(word) {
+		p.fail(fmt.Sprintf("expected %q", word))
+	}
+}
+
+func isIdentChar(c byte, digits bool) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || digits && isDigit(c)
+}
+
+// ident parses identifier at the current position
+func (p *transformParser) ident() string {
+	start := p.pos
+	if !isIdentChar(p.peek(), false) {
+		p.fail("expected identifier")
+	}
+	for isIdentChar(p.peek(), true) {
+		p.pos++
+	}
+	return p.expr[start:p.pos]
+}
+
+// variable parses $name
+func (p *transformParser) variable() string {
+	p.skipBlank()
+	if p.peek() != '$' {
+		p.fail("expected variable")
+	}
+	p.pos++
+	return p.ident()
+}
+
+// bound reports whether variable is in scope
+func (p *transformParser) bound(name string) bool {
+	for i := len(p.vars) - 1; i >= 0; i-- {
+		if p.vars[i] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// scoped parses body with variable bound
+func (p *transformParser) scoped(name string, parse func() transformExpr) transformExpr {
+	p.vars = append(p.vars, name)
+	body := parse()
+	p.vars = p.vars[:len(p.vars)-1]
+	return body
+}
+
+// region operators
+
+// parsePipe parses "a | b" and "term as $x | body", the lowest precedence level
+func (p *transformParser) parsePipe() transformExpr {
+	p.skipBlank()
+	start := p.pos
+	left := p.parseComma()
+	p.skipBlank()
+	end := p.pos
+	if p.keyword("as") {
+		if p.termStart != start || p.termEnd != end {
+			p.failAt(end, "only a term can be bound to a variable")
+		}
+		name := p.variable()
+		if p.operator("|") == "" {
+			p.fail("expected '|'")
+		}
+		return &bindExpr{source: left, name: name, body: p.scoped(name, p.parsePipe)}
+	}
+	if p.operator("|") != "" {
+		return &pipeExpr{left: left, right: p.parsePipe()}
+	}
+	return left
+}
+
+func (p *transformParser) parseComma() transformExpr {
+	left := p.parseAlternative()
+	for p.operator(",") != "" {
+		left = &commaExpr{left: left, right: p.parseAlternative()}
+	}
+	return left
+}
+
+// parseAlternative parses right associative "a // b"
+func (p *transformParser) parseAlternative() transformExpr {
+	left := p.parseOr()
+	if p.operator("//") != "" {
+		return &alternativeExpr{left: left, right: p.parseAlternative()}
+	}
+	return left
+}
+
+func (p *transformParser) parseOr() transformExpr {
+	left := p.parseAnd()
+	for p.keyword("or") {
+		left = &logicExpr{left: left, right: p.parseAnd()}
+	}
+	return left
+}
+
+func (p *transformParser) parseAnd() transformExpr {
+	left := p.parseComparison()
+	for p.keyword("and") {
+		left = &logicExpr{and: true, left: left, right: p.parseComparison()}
+	}
+	return left
+}
+
+var comparisonOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseComparison parses non-associative comparison
+func (p *transformParser) parseComparison() transformExpr {
+	left := p.parseAdditive()
+	op := p.operator(comparisonOps...)
+	if op == "" {
+		if p.peek() == '=' {
+			p.fail("assignment operators are not supported")
+		}
+		return left
+	}
+	left = &binaryExpr{op: op, left: left, right: p.parseAdditive()}
+	start := p.pos
+	if p.operator(comparisonOps...) != "" {
+		p.failAt(start, "comparison operators are non-associative")
+	}
+	return left
+}
+
+func (p *transformParser) parseAdditive() transformExpr {
+	left := p.parseMultiplicative()
+	for {
+		op := p.operator("+", "-")
+		if op == "" {
+			return left
+		}
+		left = &binaryExpr{op: op, left: left, right: p.parseMultiplicative()}
+	}
+}
+
+func (p *transformParser) parseMultiplicative() transformExpr {
+	left := p.parseUnary()
+	for {
+		op := p.operator("*", "/", "%")
+		if op == "" {
+			return left
+		}
+		left = &binaryExpr{op: op, left: left, right: p.parseUnary()}
+	}
+}
+
+// parseUnary parses negation, negative number literals are folded
+func (p *transformParser) parseUnary() transformExpr {
+	if p.operator("-") == "" {
+		return p.parsePostfix()
+	}
+	body := p.parseUnary()
+	if c, ok := body.(*constExpr); ok && isNumber(c.value) {
+		return &constExpr{value: negateNumber(c.value)}
+	}
+	return &negateExpr{body: body}
+}
+
+// endregion
+
+// region terms
+
+// parsePostfix parses term followed by .foo, [...] and ? suffixes
+func (p *transformParser) parsePostfix() transformExpr {
+	p.skipBlank()
+	start := p.pos
+	term := p.parseTerm()
+	for {
+		end := p.pos
+		p.skipBlank()
+		switch c := p.peek(); {
+		case c == '?':
+			p.pos++
+			term = &tryExpr{body: term}
+		case c == '[':
+			p.pos++
+			term = p.parseBracket(term)
+		case c == '.' && (isIdentChar(p.peekAt(1), false) || p.peekAt(1) == '"'):
+			p.pos++
+			term = &indexExpr{target: term, index: p.parseField()}
+		default:
+			p.termStart, p.termEnd = start, p.pos
+			p.pos = end
+			return term
+		}
+	}
+}
+
+// parseField parses name or string after dot
+func (p *transformParser) parseField() transformExpr {
+	if p.peek() == '"' {
+		return p.parseString()
+	}
+	return &constExpr{value: stringNode(p.ident())}
+}
+
+// parseBracket parses [], [index] and [from:to] after target
+func (p *transformParser) parseBracket(target transformExpr) transformExpr {
+	if p.consume(']') {
+		return &iterateExpr{target: target}
+	}
+	var index transformExpr
+	if p.consume(':') {
+		to := p.parsePipe()
+		p.expect(']')
+		return &sliceExpr{target: target, to: to}
+	}
+	index = p.parsePipe()
+	if p.consume(':') {
+		slice := &sliceExpr{target: target, from: index}
+		if !p.consume(']') {
+			slice.to = p.parsePipe()
+			p.expect(']')
+		}
+		return slice
+	}
+	p.expect(']')
+	return &indexExpr{target: target, index: index}
+}
+
+func (p *transformParser) parseTerm() transformExpr {
+	p.skipBlank()
+	start := p.pos
+	c := p.peek()
+	switch {
+	case c == '.' && p.peekAt(1) == '.':
+		p.pos += 2
+		return recurseExpr{}
+	case c == '.':
+		p.pos++
+		if isIdentChar(p.peek(), false) || p.peek() == '"' {
+			return &indexExpr{target: identityExpr{}, index: p.parseField()}
+		}
+		return identityExpr{}
+	case c == '$':
+		name := p.variable()
+		if !p.bound(name) {
+			p.failAt(start, "undefined variable $"+name)
+		}
+		return &varExpr{name: name}
+	case c == '"':
+		return p.parseString()
+	case c == '(':
+		p.pos++
+		body := p.parsePipe()
+		p.expect(')')
+		return body
+	case c == '[':
+		p.pos++
+		if p.consume(']') {
+			return &arrayExpr{}
+		}
+		body := p.parsePipe()
+		p.expect(']')
+		return &arrayExpr{body: body}
+	case c == '{':
+		p.pos++
+		return p.parseObject()
+	case isDigit(c):
+		return &constExpr{value: p.parseNumber()}
+	case isIdentChar(c, false):
+		return p.parseWord()
+	case c == '@':
+		p.fail("format strings are not supported")
+	}
+	p.fail("expected expression")
+	return nil
+}
+
+// parseWord parses literal, keyword expression or function call
+func (p *transformParser) parseWord() transformExpr {
+	start := p.pos
+	word := p.ident()
+	switch word {
+	case "null":
+		return &constExpr{value: nullNode()}
+	case "true", "false":
+		return &constExpr{value: boolNode(word == "true")}
+	case "if":
+		return p.parseIf()
+	case "reduce", "foreach":
+		return p.parseReduce(word == "foreach")
+	case "try":
+		e := &tryExpr{body: p.parsePostfix()}
+		if p.keyword("catch") {
+			e.handler = p.parsePostfix()
+		}
+		return e
+	}
+	if transformKeywords[word] {
+		p.failAt(start, "unexpected keyword "+word)
+	}
+	var args []transformExpr
+	if p.consume('(') {
+		for {
+			args = append(args, p.parsePipe())
+			if !p.consume(';') {
+				break
+			}
+		}
+		p.expect(')')
+	}
+	name := fmt.Sprintf("%s/%d", word, len(args))
+	fn, found := transformBuiltins[name]
+	if !found {
+		p.failAt(start, "unknown function "+name)
+	}
+	return &callExpr{name: name, args: args, fn: fn}
+}
+
+// parseIf parses the rest of if-then-elif-else-end
+func (p *transformParser) parseIf() transformExpr {
+	e := &ifExpr{cond: p.parsePipe()}
+	p.expectKeyword("then")
+	e.then = p.parsePipe()
+	switch {
+	case p.keyword("elif"):
+		e.otherwise = p.parseIf()
+		return e
+	case p.keyword("else"):
+		e.otherwise = p.parsePipe()
+	default:
+		e.otherwise = identityExpr{}
+	}
+	p.expectKeyword("end")
+	return e
+}
+
+// parseReduce parses the rest of "reduce term as $x (init; update)" or "foreach term as $x (init; update; extract)"
+func (p *transformParser) parseReduce(foreach bool) transformExpr {
+	source := p.parsePostfix()
+	p.expectKeyword("as")
+	name := p.variable()
+	p.expect('(')
+	init := p.parsePipe()
+	p.expect(';')
+	update := p.scoped(name, p.parsePipe)
+	var extract transformExpr
+	if foreach && p.consume(';') {
+		extract = p.scoped(name, p.parsePipe)
+	}
+	p.expect(')')
+	if foreach {
+		return &foreachExpr{source: source, name: name, init: init, update: update, extract: extract}
+	}
+	return &reduceExpr{source: source, name: name, init: init, update: update}
+}
+
+// parseObject parses object construction after '{'
+// {a}, {"a"} and {$a} are shorthands for {a: .a}, {"a": .a} and {a: $a}
+func (p *transformParser) parseObject() transformExpr {
+	e := &objectExpr{}
+	if p.consume('}') {
+		return e
+	}
+	for {
+		p.skipBlank()
+		start := p.pos
+		var entry objectEntry
+		switch c := p.peek(); {
+		case c == '$':
+			name := p.variable()
+			if !p.bound(name) {
+				p.failAt(start, "undefined variable $"+name)
+			}
+			entry = objectEntry{key: &constExpr{value: stringNode(name)}, value: &varExpr{name: name}}
+		case c == '(':
+			p.pos++
+			entry.key = p.parsePipe()
+			p.expect(')')
+			p.expect(':')
+			entry.value = p.parseObjectValue()
+		case c == '"' || isIdentChar(c, false):
+			if c == '"' {
+				entry.key = p.parseString()
+			} else {
+				entry.key = &constExpr{value: stringNode(p.ident())}
+			}
+			if p.consume(':') {
+				entry.value = p.parseObjectValue()
+			} else {
+				entry.value = &indexExpr{target: identityExpr{}, index: entry.key}
+			}
+		default:
+			p.fail("expected object key")
+		}
+		e.entries = append(e.entries, entry)
+		if !p.consume(',') {
+			break
+		}
+	}
+	p.expect('}')
+	return e
+}
+
+// parseObjectValue parses value of object entry, unlike elsewhere comma ends it
+func (p *transformParser) parseObjectValue() transformExpr {
+	left := p.parseAlternative()
+	if p.operator("|") != "" {
+		return &pipeExpr{left: left, right: p.parseObjectValue()}
+	}
+	return left
+}
+
+// parseNumber parses number literal
+func (p *transformParser) parseNumber() *GoJSON {
+	start := p.pos
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+	if p.peek() == '.' && isDigit(p.peekAt(1)) {
+		p.pos++
+		for isDigit(p.peek()) {
+			p.pos++
+		}
+	}
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+		if c := p.peek(); c == '+' || c == '-' {
+			p.pos++
+		}
+		for isDigit(p.peek()) {
+			p.pos++
+		}
+	}
+	node, err := Parse([]byte(p.expr[start:p.pos]), ParseOptions{Strict: true})
+	if err != nil {
+		p.failAt(start, "invalid number")
+	}
+	return node
+}
+
+// parseString parses string literal with \(expr) interpolation
+func (p *transformParser) parseString() transformExpr {
+	p.pos++
+	var parts []transformExpr
+	var b strings.Builder
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			if parts == nil {
+				return &constExpr{value: stringNode(b.String())}
+			}
+			if b.Len() > 0 {
+				parts = append(parts, &constExpr{value: stringNode(b.String())})
+			}
+			return &stringExpr{parts: parts}
+		case c == '\\' && p.peekAt(1) == '(':
+			if b.Len() > 0 {
+				parts = append(parts, &constExpr{value: stringNode(b.String())})
+				b.Reset()
+			}
+			p.pos += 2
+			parts = append(parts, p.parsePipe())
+			p.expect(')')
+			continue
+		case c == '\\':
+			r, next, err := decodeEscape(p.expr, p.pos, '"')
+			if p.pos = next; err != nil {
+				p.fail(err.Error())
+			}
+			b.WriteRune(r)
+			continue
+		case c < 0x20:
+			p.fail("control character in string")
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	p.fail("unterminated string")
+	return nil
+}
+
+// endregion
@@ -0,0 +1,136 @@
+package gojson
+
+import (
+	"testing"
+)
+
+func TestGoJSON_Transform(t *testing.T) {
+	input := []byte(`{"user": {"name": "Ann", "tags": ["a", "b"]}, "items": [
+		{"id": 1, "price": 10, "qty": 2},
+		{"id": 2, "price": 2.5, "qty": 4},
+		{"id": 3, "price": 7, "qty": 0, "gift": true}
+	], "n": null}`)
+	json := Unmarshal(input)
+	cases := map[string]string{
+		`.user.name`:                                                                 `["Ann"]`,
+		`.user | .tags[1], .name`:                                                    `["b","Ann"]`,
+		`.items[].id`:                                                                `[1,2,3]`,
+		`.items[-1].id, .items[5], .n.x, .n[0]`:                                      `[3,null,null,null]`,
+		`.items[1:][].id`:                                                            `[2,3]`,
+		`.items[:-2] | length`:                                                       `[1]`,
+		`.user.tags[-1:]`:                                                            `[["b"]]`,
+		`"héllo"[1:3]`:                                                               `["él"]`,
+		`.user["name", "tags"]`:                                                      `["Ann",["a","b"]]`,
+		`[.items[] | .price * .qty]`:                                                 `[[20,10,0]]`,
+		`.items | map(.price * .qty) | add`:                                          `[30]`,
+		`.items | map(select(.qty > 0) | .id)`:                                       `[[1,2]]`,
+		`.items[] | select(.gift) | .id`:                                             `[3]`,
+		`{name: .user.name, count: (.items | length)}`:                               `[{"name":"Ann","count":3}]`,
+		`.user | {name, "first": .tags[0], (.name): 1}`:                              `[{"name":"Ann","first":"a","Ann":1}]`,
+		`{a: (1, 2), b: (3, 4)} | [.a, .b]`:                                          `[[1,3],[1,4],[2,3],[2,4]]`,
+		`.user | to_entries`:                                                         `[[{"key":"name","value":"Ann"},{"key":"tags","value":["a","b"]}]]`,
+		`[{"k": "a", "v": 1}, {"name": 2, "value": 3}] | from_entries`:               `[{"a":1,"2":3}]`,
+		`.user | with_entries({key: (.key | ascii_upcase), value})`:                  `[{"NAME":"Ann","TAGS":["a","b"]}]`,
+		`reduce .items[] as $item (0; . + $item.qty)`:                                `[6]`,
+		`reduce range(5) as $i ([]; . + [$i * 2])`:                                   `[[0,2,4,6,8]]`,
+		`[foreach (1, 2, 3) as $x (0; . + $x)]`:                                      `[[1,3,6]]`,
+		`[foreach .items[] as $i (0; . + $i.id; [$i.id, .])]`:                        `[[[1,1],[2,3],[3,6]]]`,
+		`"\(.user.name) has \(.user.tags | length) tags"`:                            `["Ann has 2 tags"]`,
+		`"tags: \(.user.tags)"`:                                                      `["tags: [\"a\",\"b\"]"]`,
+		`.missing // "default", (.n // 1), (false // null // 3)`:                     `["default",1,3]`,
+		`[.items[] | .gift // false]`:                                                `[[false,false,true]]`,
+		`(.items[0].id as $x | .items[] | select(.id > $x) | .id)`:                   `[2,3]`,
+		`.user.tags | . as $t | $t + ["c"] - ["a"]`:                                  `[["b","c"]]`,
+		`{a: 1, b: {c: 2}} * {b: {d: 3}} + {e: 4}`:                                   `[{"a":1,"b":{"c":2,"d":3},"e":4}]`,
+		`10 / 4, 10 / 5, 7 % 3, -7 % 3, 1 - 3, -(1 + 2), 0.1 + 0.2`:                  `[2.5,2,1,-1,-2,-3,0.30000000000000004]`,
+		`100000000000000000000 + 1, 2 * 1e2`:                                         `[100000000000000000001,200]`,
+		`"a,b,c" / ",", "ab" * 2, "x" + null`:                                        `[["a","b","c"],"abab","x"]`,
+		`[1, 2] == [1, 2.0], {"a": 1} != {"a": 1}, "b" > "a", null < false, [] > {}`: `[true,false,true,true,false]`,
+		`true and (true, false), (false, true) or false`:                             `[true,false,false,true]`,
+		`if .n then 1 elif .user then 2 else 3 end, (false | if . then 1 end)`:       `[2,false]`,
+		`try error("x") catch ., try (1, error("y"), 2), (.user.name | tonumber?)`:   `["x",1]`,
+		`.items | sort_by(-.price) | map(.id)`:                                       `[[1,3,2]]`,
+		`[3, 1, [2], null, "a", {}, true] | sort`:                                    `[[null,true,1,3,"a",[2],{}]]`,
+		`.items | group_by(.qty > 0) | map(map(.id))`:                                `[[[3],[1,2]]]`,
+		`[1, 2, 1, 3.0, 3] | unique, min, max`:                                       `[[1,2,3.0],1,3]`,
+		`.items | min_by(.price).id, max_by(.qty).id`:                                `[2,2]`,
+		`.user | keys, has("tags"), (.tags | has(5))`:                                `[["name","tags"],true,false]`,
+		`[.user.tags[] | ascii_upcase] | join("-"), ("a-b" | split("-"))`:            `["A-B",["a","b"]]`,
+		`[limit(2; .items[].id)], first(.items[].id), (.items | first.id, last.id)`:  `[[1,2],1,1,3]`,
+		`[range(3)], [range(1; 3)], ([[1, [2]], 3] | flatten)`:                       `[[0,1,2],[1,2],[1,2,3]]`,
+		`.items[0] | tojson, (tojson | fromjson | .id), (.id | tostring), type`:      `["{\"id\":1,\"price\":10,\"qty\":2}",1,"1","object"]`,
+		`[.user | ..] | length`:                                                      `[5]`,
+		`{"a": {"b": [1]}} | contains({a: {b: []}}), ([1, 2] | any, all)`:            `[true,true,true]`,
+		`(.items | map_values(.id)), (.user | map_values(empty))`:                    `[[1,2,3],{}]`,
+		`"abc" | length, utf8bytelength, (-5 | length), ([1] | reverse)`:             `[3,3,5,[1]]`,
+		`# comment
+		.user.name # trailing`: `["Ann"]`,
+	}
+	for expr, expected := range cases {
+		out, err := json.Transform(expr)
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		if result := joinNodes(out); result != expected {
+			t.Fatalf("%s: expected %s, got %s", expr, expected, result)
+		}
+	}
+	if out := string(json.Marshal()); out != string(Unmarshal(input).Marshal()) {
+		t.Fatal("input was modified", out)
+	}
+}
+
+func TestCompileTransform(t *testing.T) {
+	invalid := []string{
+		``, `.a |`, `.a | = 1`, `.a |= 1`, `.a += 1`, `.[`, `{a: 1`, `{1: 2}`, `"abc`, `"\q"`, `$x`, `1 == 2 == 3`,
+		`. as $x`, `1 + . as $x | $x`, `if . then 1`, `reduce . as $x (0)`, `nosuch`, `map`, `length(1)`,
+		`@base64`, `def f: 1; f`, `.a .b )`, `try`, `and`,
+	}
+	for _, expr := range invalid {
+		_, err := CompileTransform(expr)
+		if _, ok := err.(*TransformError); !ok {
+			t.Fatalf("%q: expected *TransformError, got %v", expr, err)
+		}
+	}
+
+	json := Unmarshal([]byte(`{"a": {"b": [1, 2]}, "s": "x"}`))
+	runtime := map[string]string{
+		`.s.x`:                  `cannot index string with "x"`,
+		`.a[0]`:                 `cannot index object with number`,
+		`.s[]`:                  `cannot iterate over string ("x")`,
+		`.a + 1`:                `object ({"b":[1,2]}) and number (1) cannot be added`,
+		`1 / 0`:                 `number (1) and number (0) cannot be divided because the divisor is zero`,
+		`error({"code": 1})`:    `{"code":1} (not a string)`,
+		`.a.b | sort_by(error)`: `1 (not a string)`,
+	}
+	for expr, msg := range runtime {
+		out, err := json.Transform(expr)
+		evalErr, ok := err.(*EvalError)
+		if !ok || evalErr.Error() != msg || out != nil {
+			t.Fatalf("%s: unexpected result %v, %v", expr, out, err)
+		}
+	}
+	// outputs before the error are returned
+	out, err := json.Transform(`.a.b[], error("stop"), 3`)
+	if joinNodes(out) != `[1,2]` || err == nil || err.Error() != "stop" {
+		t.Fatal("unexpected result", joinNodes(out), err)
+	}
+
+	// outputs are independent trees
+	tr := MustCompileTransform(`.a, {c: .a.b}`)
+	out, err = tr.Apply(json)
+	if err != nil || len(out) != 2 {
+		t.Fatal(out, err)
+	}
+	out[0].Get("b").Set(0, numberNode(9))
+	out[1].Get("c").Set(-1, numberNode(3))
+	if s := string(json.Marshal()); s != `{"a":{"b":[1,2]},"s":"x"}` {
+		t.Fatal("input was modified", s)
+	}
+	if p := json.Get("a").Get("b").Pointer(); p != "/a/b" {
+		t.Fatal("input parents were modified", p)
+	}
+	if out[1].Get("c").Get(0).Pointer() != "/c/0" {
+		t.Fatal("wrong parent of output")
+	}
+}